@@ -19,13 +19,14 @@ type apiRequestBody struct {
 }
 
 func apiHandler(w *http.ResponseWriter, req *http.Request) {
-	if req.RequestLine.Method != "POST" {
-		w.WriteStatusLine(http.StatusMethodNotAllowed)
+	body, err := req.BodyBytes()
+	if err != nil {
+		w.WriteStatusLine(http.StatusBadRequest)
 		return
 	}
 
 	var reqBody apiRequestBody
-	if err := json.Unmarshal(req.Body, &reqBody); err != nil {
+	if err := json.Unmarshal(body, &reqBody); err != nil {
 		w.WriteStatusLine(http.StatusBadRequest)
 		return
 	}
@@ -55,14 +56,6 @@ func apiHandler(w *http.ResponseWriter, req *http.Request) {
 		EvaluatedValue: strconv.FormatFloat(evaluatedValue, 'g', 9, 64),
 	}
 
-	resData, err := json.Marshal(resBody)
-	if err != nil {
-		w.WriteStatusLine(http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteStatusLine(http.StatusOK)
-	h := http.GetDefaultResponseHeaders("application/json", len(resData))
-	w.WriteHeaders(h)
-	w.WriteBody(resData)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resBody)
 }
@@ -1,26 +1,35 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/debobrad579/httpfromtcp/internal/http"
 )
 
 const port = 8080
+const shutdownTimeout = 10 * time.Second
 
 func main() {
-	server, err := http.ListenAndServe(port, routeHandler)
+	server, err := http.ListenAndServe(port, newRouter().Serve)
 	if err != nil {
 		log.Fatalf("Error starting server: %v", err)
 	}
-	defer server.Close()
 	log.Println("Server started on port", port)
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+		return
+	}
 	log.Println("Server gracefully stopped")
 }
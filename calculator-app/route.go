@@ -1,29 +1,24 @@
 package main
 
 import (
-	"log"
-	"strings"
+	"path/filepath"
 
 	"github.com/debobrad579/httpfromtcp/internal/http"
 )
 
-func routeHandler(w *http.ResponseWriter, req *http.Request) {
-	log.Printf("%s %s %s", req.RequestLine.Method, req.RequestLine.RequestTarget, req.Body)
-
-	if req.RequestLine.RequestTarget == "/" {
-		writeFileResponse(w, "calculator-app/templates/index.html")
-		return
-	}
-
-	if strings.HasPrefix(req.RequestLine.RequestTarget, "/static/") {
-		writeFileResponse(w, "calculator-app"+req.RequestLine.RequestTarget)
-		return
-	}
+func newRouter() *http.Router {
+	router := http.NewRouter()
+	router.Use(http.Recover, http.LogRequests)
+	router.Handle("GET", "/", indexHandler)
+	router.Handle("GET", "/static/{path...}", staticHandler)
+	router.Handle("POST", "/api", apiHandler)
+	return router
+}
 
-	if req.RequestLine.RequestTarget == "/api" {
-		apiHandler(w, req)
-		return
-	}
+func indexHandler(w *http.ResponseWriter, req *http.Request) {
+	writeFileResponse(w, "calculator-app/templates/index.html")
+}
 
-	w.WriteStatusLine(http.StatusNotFound)
+func staticHandler(w *http.ResponseWriter, req *http.Request) {
+	writeFileResponse(w, filepath.Join("calculator-app/static", filepath.Clean("/"+req.PathValue("path"))))
 }
@@ -1,38 +1,51 @@
 package main
 
 import (
+	"context"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/debobrad579/httpfromtcp/internal/http"
 )
 
 const port = 42069
+const shutdownTimeout = 10 * time.Second
 
 func main() {
-	server, err := http.ListenAndServe(port, handler)
+	server, err := http.ListenAndServe(port, newRouter().Serve)
 	if err != nil {
 		log.Fatalf("Error starting server: %v", err)
 	}
-	defer server.Close()
 	log.Println("Server started on port", port)
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+		return
+	}
 	log.Println("Server gracefully stopped")
 }
 
-func handler(w *http.ResponseWriter, req *http.Request) {
-	log.Printf("%s %s", req.RequestLine.Method, req.RequestLine.RequestTarget)
-
-	var html string
+func newRouter() *http.Router {
+	router := http.NewRouter()
+	router.Use(http.Recover, http.LogRequests)
+	router.Handle("", "/yourproblem", yourProblemHandler)
+	router.Handle("", "/myproblem", myProblemHandler)
+	router.Handle("", "/{path...}", successHandler)
+	return router
+}
 
-	switch req.RequestLine.RequestTarget {
-	case "/yourproblem":
-		html = `<html>
+func yourProblemHandler(w *http.ResponseWriter, req *http.Request) {
+	html := `<html>
   <head>
     <title>400 Bad Request</title>
   </head>
@@ -42,9 +55,11 @@ func handler(w *http.ResponseWriter, req *http.Request) {
   </body>
 </html>`
 
-		w.WriteStatusLine(http.StatusBadRequest)
-	case "/myproblem":
-		html = `<html>
+	writeHTML(w, http.StatusBadRequest, html)
+}
+
+func myProblemHandler(w *http.ResponseWriter, req *http.Request) {
+	html := `<html>
   <head>
     <title>500 Internal Server Error</title>
   </head>
@@ -54,9 +69,11 @@ func handler(w *http.ResponseWriter, req *http.Request) {
   </body>
 </html>`
 
-		w.WriteStatusLine(http.StatusInternalServerError)
-	default:
-		html = `<html>
+	writeHTML(w, http.StatusInternalServerError, html)
+}
+
+func successHandler(w *http.ResponseWriter, req *http.Request) {
+	html := `<html>
   <head>
     <title>200 OK</title>
   </head>
@@ -66,10 +83,11 @@ func handler(w *http.ResponseWriter, req *http.Request) {
   </body>
 </html>`
 
-		w.WriteStatusLine(http.StatusOK)
-	}
+	writeHTML(w, http.StatusOK, html)
+}
 
-	headers := http.GetDefaultResponseHeaders("text/html", len(html))
-	w.WriteHeaders(headers)
-	w.WriteBody([]byte(html))
+func writeHTML(w *http.ResponseWriter, statusCode http.StatusCode, html string) {
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteStatusLine(statusCode)
+	io.WriteString(w, html)
 }
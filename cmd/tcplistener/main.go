@@ -35,7 +35,12 @@ func main() {
 			fmt.Printf("- %s: %s\n", fieldName, fieldValue)
 			return true
 		})
-		fmt.Printf("Body:\n%s\n", string(req.Body))
+		body, err := req.BodyBytes()
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("Body:\n%s\n", string(body))
 
 		fmt.Println("Connection closed")
 	}
@@ -0,0 +1,287 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type bodyState int
+
+const (
+	bodyReadingData bodyState = iota
+	bodyReadingChunkSize
+	bodyReadingChunkData
+	bodyReadingChunkDataEnd
+	bodyReadingTrailers
+	bodyDone
+)
+
+type body struct {
+	rr          *RequestReader
+	trailers    *Headers
+	state       bodyState
+	remaining   int
+	bytesRead   int64
+	trailerSize int
+	onEOF       func()
+	onFirstRead func() error
+	closed      bool
+	err         error
+}
+
+func newBody(rr *RequestReader, req *Request) (*body, error) {
+	b := &body{
+		rr:       rr,
+		trailers: req.Trailers,
+		state:    bodyDone,
+	}
+
+	contentLengths := req.Headers.Values("Content-Length")
+	if len(req.Headers.Values("Transfer-Encoding")) > 0 {
+		if len(contentLengths) > 0 {
+			return nil, newParseError(StatusBadRequest, "request has both Content-Length and Transfer-Encoding")
+		}
+		if req.RequestLine.HttpVersion == "1.0" {
+			return nil, newParseError(StatusBadRequest, "transfer encoding in HTTP/1.0 request")
+		}
+
+		transferEncoding := req.Headers.Get("Transfer-Encoding")
+		if !strings.EqualFold(transferEncoding, "chunked") {
+			return nil, newParseError(StatusNotImplemented, "unsupported transfer encoding: %s", transferEncoding)
+		}
+
+		b.state = bodyReadingChunkSize
+		return b, nil
+	}
+
+	if len(contentLengths) == 0 {
+		return b, nil
+	}
+
+	contentLength, err := parseContentLength(contentLengths)
+	if err != nil {
+		return nil, asParseError(err, StatusBadRequest)
+	}
+
+	if b.exceedsLimit(int64(contentLength)) {
+		return nil, newParseError(StatusRequestEntityTooLarge, "request body too large")
+	}
+
+	if contentLength > 0 {
+		b.state = bodyReadingData
+		b.remaining = contentLength
+	}
+
+	return b, nil
+}
+
+func parseContentLength(values []string) (int, error) {
+	contentLength := ""
+	for _, value := range values {
+		for v := range strings.SplitSeq(value, ",") {
+			v = strings.TrimSpace(v)
+			if !isDigits(v) {
+				return 0, fmt.Errorf("invalid content length: %q", value)
+			}
+			if contentLength != "" && v != contentLength {
+				return 0, fmt.Errorf("conflicting content lengths: %s, %s", contentLength, v)
+			}
+			contentLength = v
+		}
+	}
+
+	n, err := strconv.Atoi(contentLength)
+	if err != nil {
+		return 0, fmt.Errorf("invalid content length: %s", contentLength)
+	}
+
+	return n, nil
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	if b.closed {
+		return 0, errors.New("read on closed body")
+	}
+
+	return b.read(p)
+}
+
+func (b *body) Close() error {
+	b.closed = true
+	return nil
+}
+
+func (b *body) drain() error {
+	buf := make([]byte, bufferSize)
+	for {
+		_, err := b.read(buf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (b *body) read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	if b.onFirstRead != nil && b.state != bodyDone {
+		onFirstRead := b.onFirstRead
+		b.onFirstRead = nil
+		if err := onFirstRead(); err != nil {
+			b.err = err
+			return 0, err
+		}
+	}
+
+	n, err := b.readSingle(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+
+	return n, err
+}
+
+func (b *body) readSingle(p []byte) (int, error) {
+	if len(p) == 0 && b.state != bodyDone {
+		return 0, nil
+	}
+
+	for {
+		switch b.state {
+		case bodyReadingData, bodyReadingChunkData:
+			if b.remaining == 0 {
+				if b.state == bodyReadingData {
+					b.state = bodyDone
+				} else {
+					b.state = bodyReadingChunkDataEnd
+				}
+				continue
+			}
+
+			buffered := b.rr.buffered()
+			if len(buffered) == 0 {
+				if err := b.fill(); err != nil {
+					return 0, err
+				}
+				continue
+			}
+
+			n := copy(p[:min(len(p), b.remaining)], buffered)
+			b.rr.discard(n)
+			b.remaining -= n
+			return n, nil
+		case bodyReadingChunkSize:
+			chunkSize, n, err := parseChunkSize(b.rr.buffered())
+			if err != nil {
+				return 0, asParseError(err, StatusBadRequest)
+			}
+
+			if n == 0 {
+				if err := b.fill(); err != nil {
+					return 0, err
+				}
+				continue
+			}
+
+			b.rr.discard(n)
+			if b.rr.MaxBodyBytes > 0 && int64(chunkSize) > b.rr.MaxBodyBytes-b.bytesRead {
+				return 0, newParseError(StatusRequestEntityTooLarge, "request body too large")
+			}
+
+			b.bytesRead += int64(chunkSize)
+			b.remaining = chunkSize
+			if chunkSize == 0 {
+				b.state = bodyReadingTrailers
+			} else {
+				b.state = bodyReadingChunkData
+			}
+		case bodyReadingChunkDataEnd:
+			buffered := b.rr.buffered()
+			if len(buffered) < 2 {
+				if err := b.fill(); err != nil {
+					return 0, err
+				}
+				continue
+			}
+
+			if !bytes.HasPrefix(buffered, []byte("\r\n")) {
+				return 0, newParseError(StatusBadRequest, "missing CRLF after chunk data")
+			}
+
+			b.rr.discard(2)
+			b.state = bodyReadingChunkSize
+		case bodyReadingTrailers:
+			n, done, err := b.trailers.Parse(b.rr.buffered())
+			if err != nil {
+				return 0, asParseError(err, StatusBadRequest)
+			}
+
+			if n == 0 {
+				if err := b.fill(); err != nil {
+					return 0, err
+				}
+				continue
+			}
+
+			b.rr.discard(n)
+			b.trailerSize += n
+			if exceedsLimit(b.trailerSize, b.rr.MaxHeaderBytes) || exceedsLimit(b.trailers.Len(), b.rr.MaxHeaderFields) {
+				return 0, newParseError(StatusRequestHeaderFieldsTooLarge, "request trailers too large")
+			}
+
+			if done {
+				b.state = bodyDone
+			}
+		case bodyDone:
+			b.notifyEOF()
+			return 0, io.EOF
+		default:
+			return 0, errors.New("unknown body state")
+		}
+	}
+}
+
+func (b *body) setOnEOF(onEOF func()) {
+	b.onEOF = onEOF
+	if b.state == bodyDone {
+		b.notifyEOF()
+	}
+}
+
+func (b *body) setOnFirstRead(onFirstRead func() error) {
+	b.onFirstRead = onFirstRead
+}
+
+func (b *body) awaitingFirstRead() bool {
+	return b.onFirstRead != nil && b.state != bodyDone
+}
+
+func (b *body) notifyEOF() {
+	if b.onEOF != nil {
+		onEOF := b.onEOF
+		b.onEOF = nil
+		onEOF()
+	}
+}
+
+func (b *body) exceedsLimit(size int64) bool {
+	return b.rr.MaxBodyBytes > 0 && size > b.rr.MaxBodyBytes
+}
+
+func (b *body) fill() error {
+	err := b.rr.fill()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
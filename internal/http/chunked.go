@@ -0,0 +1,112 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const maxChunkSize = math.MaxInt32
+
+func parseChunkSize(data []byte) (int, int, error) {
+	i := bytes.Index(data, []byte("\r\n"))
+	if i == -1 {
+		return 0, 0, nil
+	}
+
+	sizeStr, extensions, _ := strings.Cut(string(data[:i]), ";")
+	sizeStr = strings.TrimRight(sizeStr, " \t")
+	if sizeStr == "" {
+		return 0, 0, errors.New("missing chunk size")
+	}
+
+	for _, c := range sizeStr {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return 0, 0, fmt.Errorf("invalid chunk size: %s", sizeStr)
+		}
+	}
+
+	chunkSize, err := strconv.ParseInt(sizeStr, 16, 64)
+	if err != nil || chunkSize > maxChunkSize {
+		return 0, 0, fmt.Errorf("invalid chunk size: %s", sizeStr)
+	}
+
+	if extensions != "" {
+		if err := validateChunkExtensions(extensions); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return int(chunkSize), i + 2, nil
+}
+
+// validateChunkExtensions scans the extensions after the first ";" as
+// tokens and quoted strings, so a ";" inside quotes does not split them.
+func validateChunkExtensions(extensions string) error {
+	rest := extensions
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		n := tokenLen(rest)
+		if n == 0 {
+			return fmt.Errorf("invalid chunk extension: %s", extensions)
+		}
+		rest = strings.TrimLeft(rest[n:], " \t")
+
+		if strings.HasPrefix(rest, "=") {
+			rest = strings.TrimLeft(rest[1:], " \t")
+			if strings.HasPrefix(rest, `"`) {
+				n = quotedStringLen(rest)
+			} else {
+				n = tokenLen(rest)
+			}
+			if n == 0 {
+				return fmt.Errorf("invalid chunk extension: %s", extensions)
+			}
+			rest = strings.TrimLeft(rest[n:], " \t")
+		}
+
+		if rest == "" {
+			return nil
+		}
+		if rest[0] != ';' {
+			return fmt.Errorf("invalid chunk extension: %s", extensions)
+		}
+		rest = rest[1:]
+	}
+}
+
+func tokenLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		if !isValidFieldName(strings.ToLower(s[i : i+1])) {
+			return i
+		}
+	}
+
+	return len(s)
+}
+
+// quotedStringLen returns the length of the quoted string at the start of s,
+// or 0 if it is unterminated or contains a control character.
+func quotedStringLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '"' {
+			return i + 1
+		}
+		if c == '\\' {
+			i++
+			if i == len(s) {
+				return 0
+			}
+			c = s[i]
+		}
+		if (c < ' ' && c != '\t') || c == 0x7f {
+			return 0
+		}
+	}
+
+	return 0
+}
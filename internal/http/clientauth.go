@@ -0,0 +1,69 @@
+package http
+
+import (
+	"crypto/x509"
+	"errors"
+	"os"
+	"slices"
+)
+
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.New("no certificates found in " + file)
+		}
+	}
+
+	return pool, nil
+}
+
+func (r *Request) ClientCertificate() *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return r.TLS.VerifiedChains[0][0]
+}
+
+func RequireClientCert(allow func(cert *x509.Certificate) bool) Middleware {
+	return func(next Handler) Handler {
+		return func(w *ResponseWriter, req *Request) {
+			cert := req.ClientCertificate()
+			if cert == nil {
+				writeStatusResponse(w, StatusUnauthorized)
+				return
+			}
+
+			if allow != nil && !allow(cert) {
+				writeStatusResponse(w, StatusForbidden)
+				return
+			}
+
+			next(w, req)
+		}
+	}
+}
+
+func AllowCommonNames(names ...string) func(cert *x509.Certificate) bool {
+	return func(cert *x509.Certificate) bool {
+		return slices.Contains(names, cert.Subject.CommonName)
+	}
+}
+
+func AllowDNSNames(names ...string) func(cert *x509.Certificate) bool {
+	return func(cert *x509.Certificate) bool {
+		for _, dnsName := range cert.DNSNames {
+			if slices.Contains(names, dnsName) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+)
+
+const (
+	defaultReadTimeout         = 60 * time.Second
+	defaultReadHeaderTimeout   = 10 * time.Second
+	defaultWriteTimeout        = 60 * time.Second
+	defaultIdleTimeout         = 60 * time.Second
+	defaultMaxRequestLineBytes = 8 * 1024
+	defaultMaxHeaderBytes      = 1024 * 1024
+	defaultMaxHeaderFields     = 100
+	defaultMaxBodyBytes        = 8 * 1024 * 1024
+	defaultMaxRequestsPerConn  = 100
+)
+
+// ServerConfig holds the limits and hooks used by a Server. Zero values fall
+// back to the package defaults; negative values disable the limit.
+type ServerConfig struct {
+	ReadTimeout         time.Duration
+	ReadHeaderTimeout   time.Duration
+	WriteTimeout        time.Duration
+	IdleTimeout         time.Duration
+	MaxRequestLineBytes int
+	MaxHeaderBytes      int
+	MaxHeaderFields     int
+	MaxBodyBytes        int64
+	MaxRequestsPerConn  int
+	TLSConfig           *tls.Config
+	ClientCAs           *x509.CertPool
+	ClientAuth          tls.ClientAuthType
+	ErrorHandler        func(w *ResponseWriter, err *ParseError)
+	// CheckContinue decides whether a request with Expect: 100-continue may
+	// send its body; any status other than StatusContinue is sent instead.
+	CheckContinue func(req *Request) StatusCode
+}
+
+func (c ServerConfig) withDefaults() ServerConfig {
+	if c.ReadTimeout == 0 {
+		c.ReadTimeout = defaultReadTimeout
+	}
+	if c.ReadHeaderTimeout == 0 {
+		c.ReadHeaderTimeout = defaultReadHeaderTimeout
+	}
+	if c.WriteTimeout == 0 {
+		c.WriteTimeout = defaultWriteTimeout
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = defaultIdleTimeout
+	}
+	if c.MaxRequestLineBytes == 0 {
+		c.MaxRequestLineBytes = defaultMaxRequestLineBytes
+	}
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes = defaultMaxHeaderBytes
+	}
+	if c.MaxHeaderFields == 0 {
+		c.MaxHeaderFields = defaultMaxHeaderFields
+	}
+	if c.MaxBodyBytes == 0 {
+		c.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if c.MaxRequestsPerConn == 0 {
+		c.MaxRequestsPerConn = defaultMaxRequestsPerConn
+	}
+	if c.ErrorHandler == nil {
+		c.ErrorHandler = defaultErrorHandler
+	}
+	if c.ClientCAs != nil && c.ClientAuth == tls.NoClientCert {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return c
+}
+
+func deadline(timeout time.Duration) time.Time {
+	return deadlineFrom(time.Now(), timeout)
+}
+
+func deadlineFrom(start time.Time, timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+
+	return start.Add(timeout)
+}
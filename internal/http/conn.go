@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+var aLongTimeAgo = time.Unix(1, 0)
+
+type connReader struct {
+	conn    net.Conn
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	cond    *sync.Cond
+	inRead  bool
+	aborted bool
+	hasByte bool
+	byteBuf [1]byte
+}
+
+func newConnReader(conn net.Conn, cancel context.CancelFunc) *connReader {
+	cr := &connReader{
+		conn:   conn,
+		cancel: cancel,
+	}
+	cr.cond = sync.NewCond(&cr.mu)
+	return cr
+}
+
+func (cr *connReader) Read(p []byte) (int, error) {
+	cr.mu.Lock()
+	if cr.inRead {
+		cr.mu.Unlock()
+		return 0, errors.New("concurrent read on connection")
+	}
+
+	if cr.hasByte && len(p) > 0 {
+		p[0] = cr.byteBuf[0]
+		cr.hasByte = false
+		cr.mu.Unlock()
+		return 1, nil
+	}
+	cr.mu.Unlock()
+
+	n, err := cr.conn.Read(p)
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		cr.cancel()
+	}
+
+	return n, err
+}
+
+func (cr *connReader) startBackgroundRead() {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if cr.inRead || cr.hasByte {
+		return
+	}
+
+	cr.inRead = true
+	cr.conn.SetReadDeadline(time.Time{})
+	go cr.backgroundRead()
+}
+
+func (cr *connReader) backgroundRead() {
+	n, err := cr.conn.Read(cr.byteBuf[:])
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if n == 1 {
+		cr.hasByte = true
+	}
+
+	if err != nil && !(cr.aborted && errors.Is(err, os.ErrDeadlineExceeded)) {
+		cr.cancel()
+	}
+
+	cr.aborted = false
+	cr.inRead = false
+	cr.cond.Broadcast()
+}
+
+func (cr *connReader) abortPendingRead() {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if !cr.inRead {
+		return
+	}
+
+	cr.aborted = true
+	cr.conn.SetReadDeadline(aLongTimeAgo)
+	for cr.inRead {
+		cr.cond.Wait()
+	}
+	cr.conn.SetReadDeadline(time.Time{})
+}
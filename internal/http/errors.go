@@ -0,0 +1,49 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrHTTPVersionNotSupported = errors.New("http version not supported")
+	ErrInvalidHeaderName       = errors.New("invalid header field name")
+	ErrInvalidHeaderValue      = errors.New("invalid header field value")
+	ErrBodyNotAllowed          = errors.New("response status does not allow a body")
+)
+
+type ParseError struct {
+	StatusCode StatusCode
+	Err        error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func newParseError(statusCode StatusCode, format string, args ...any) error {
+	return &ParseError{
+		StatusCode: statusCode,
+		Err:        fmt.Errorf(format, args...),
+	}
+}
+
+func asParseError(err error, statusCode StatusCode) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return err
+	}
+
+	return &ParseError{
+		StatusCode: statusCode,
+		Err:        err,
+	}
+}
+
+func defaultErrorHandler(w *ResponseWriter, err *ParseError) {
+	writeStatusResponse(w, err.StatusCode)
+}
@@ -3,43 +3,109 @@ package http
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"log"
+	"slices"
 	"strconv"
 	"strings"
 )
 
+type headerField struct {
+	name  string
+	value string
+}
+
 type Headers struct {
-	headers map[string]string
+	fields []headerField
 }
 
 func NewHeaders() *Headers {
-	h := Headers{}
-	h.headers = make(map[string]string)
-	return &h
+	return &Headers{}
 }
 
+// GetDefaultResponseHeaders logs and leaves out a Content-Type that is not a
+// valid field value instead of sending it.
 func GetDefaultResponseHeaders(mimetype string, contentLen int) *Headers {
 	h := NewHeaders()
-	h.Set("Connection", "close")
-	h.Set("Content-Type", mimetype)
+	if err := h.Set("Content-Type", mimetype); err != nil {
+		log.Println(err)
+	}
 	h.Set("Content-Length", strconv.Itoa(contentLen))
 	return h
 }
 
+// Get joins repeated fields with ", ". Use Values for fields such as
+// Set-Cookie whose values cannot be combined.
 func (h *Headers) Get(key string) string {
-	return h.headers[strings.ToLower(key)]
+	return strings.Join(h.Values(key), ", ")
+}
+
+func (h *Headers) Values(key string) []string {
+	var values []string
+	for _, field := range h.fields {
+		if strings.EqualFold(field.name, key) {
+			values = append(values, field.value)
+		}
+	}
+
+	return values
+}
+
+func (h *Headers) Set(key, value string) error {
+	if err := validateField(key, value); err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(h.fields, func(field headerField) bool {
+		return strings.EqualFold(field.name, key)
+	})
+	if i == -1 {
+		h.fields = append(h.fields, headerField{name: key, value: value})
+		return nil
+	}
+
+	rest := slices.DeleteFunc(h.fields[i+1:], func(field headerField) bool {
+		return strings.EqualFold(field.name, key)
+	})
+	h.fields[i] = headerField{name: key, value: value}
+	h.fields = h.fields[:i+1+len(rest)]
+	return nil
 }
 
-func (h *Headers) Set(key, value string) {
-	h.headers[strings.ToLower(key)] = value
+func (h *Headers) Add(key, value string) error {
+	if err := validateField(key, value); err != nil {
+		return err
+	}
+
+	h.fields = append(h.fields, headerField{name: key, value: value})
+	return nil
 }
 
 func (h *Headers) Del(key string) {
-	delete(h.headers, strings.ToLower(key))
+	h.fields = slices.DeleteFunc(h.fields, func(field headerField) bool {
+		return strings.EqualFold(field.name, key)
+	})
+}
+
+func (h *Headers) clone() *Headers {
+	return &Headers{fields: slices.Clone(h.fields)}
+}
+
+// merge replaces the fields of h with every field named in other.
+func (h *Headers) merge(other *Headers) {
+	for _, field := range other.fields {
+		h.Del(field.name)
+	}
+	h.fields = append(h.fields, other.fields...)
+}
+
+func (h *Headers) Len() int {
+	return len(h.fields)
 }
 
 func (h *Headers) Range(callback func(key, value string) bool) {
-	for k, v := range h.headers {
-		if !callback(k, v) {
+	for _, field := range h.fields {
+		if !callback(field.name, field.value) {
 			return
 		}
 	}
@@ -57,26 +123,34 @@ func (h *Headers) Parse(data []byte) (int, bool, error) {
 		return 2, true, nil
 	}
 
-	fieldName, fieldValue, found := strings.Cut(string(fieldLine), ":")
-	if !found || strings.HasSuffix(fieldName, " ") {
-		return 0, false, errors.New("invalid header format: " + fieldName)
+	if fieldLine[0] == ' ' || fieldLine[0] == '\t' {
+		return 0, false, errors.New("invalid header format: field line starts with whitespace")
 	}
 
-	fieldName = strings.ToLower(strings.TrimSpace(fieldName))
-	if !isValidFieldName(fieldName) {
+	fieldName, fieldValue, found := strings.Cut(string(fieldLine), ":")
+	if !found || strings.ContainsAny(fieldName, " \t") {
 		return 0, false, errors.New("invalid header format: " + fieldName)
 	}
 
-	fieldValue = strings.TrimSpace(fieldValue)
-	prevFieldValue := h.Get(fieldName)
-	if prevFieldValue != "" {
-		fieldValue = prevFieldValue + ", " + fieldValue
+	fieldValue = strings.Trim(fieldValue, " \t")
+	if err := h.Add(fieldName, fieldValue); err != nil {
+		return 0, false, err
 	}
 
-	h.Set(fieldName, fieldValue)
 	return i + 2, false, nil
 }
 
+func validateField(name, value string) error {
+	if !isValidFieldName(strings.ToLower(name)) {
+		return fmt.Errorf("%w: %q", ErrInvalidHeaderName, name)
+	}
+	if !isValidFieldValue(value) {
+		return fmt.Errorf("%w for %s: %q", ErrInvalidHeaderValue, name, value)
+	}
+
+	return nil
+}
+
 func isValidFieldName(s string) bool {
 	if s == "" {
 		return false
@@ -90,3 +164,13 @@ func isValidFieldName(s string) bool {
 
 	return true
 }
+
+func isValidFieldValue(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < ' ' && c != '\t') || c == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
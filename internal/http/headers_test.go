@@ -1,6 +1,9 @@
 package http_test
 
 import (
+	"bytes"
+	"log"
+	"os"
 	"testing"
 
 	"github.com/debobrad579/httpfromtcp/internal/http"
@@ -19,14 +22,22 @@ func TestHeaders(t *testing.T) {
 	assert.Equal(t, 23, n)
 	assert.False(t, done)
 
-	// Test: Valid single header with extra whitespace
+	// Test: Valid single header with extra whitespace around the value
 	h = http.NewHeaders()
-	data = []byte("       Host: localhost:42069                           \r\n\r\n")
+	data = []byte("Host:    localhost:42069                           \r\n\r\n")
 	n, done, err = h.Parse(data)
 	require.NoError(t, err)
 	require.NotNil(t, h)
 	assert.Equal(t, "localhost:42069", h.Get("host"))
-	assert.Equal(t, 57, n)
+	assert.Equal(t, 53, n)
+	assert.False(t, done)
+
+	// Test: Invalid leading whitespace before the field name
+	h = http.NewHeaders()
+	data = []byte("       Host: localhost:42069\r\n\r\n")
+	n, done, err = h.Parse(data)
+	require.Error(t, err)
+	assert.Equal(t, 0, n)
 	assert.False(t, done)
 
 	// Test: Valid 2 h with existing h
@@ -49,6 +60,7 @@ func TestHeaders(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, h)
 	assert.Equal(t, "localhost:42069, localhost:8080", h.Get("host"))
+	assert.Equal(t, []string{"localhost:42069", "localhost:8080"}, h.Values("host"))
 	assert.Equal(t, 22, n)
 	assert.False(t, done)
 
@@ -105,3 +117,129 @@ func TestHeaders(t *testing.T) {
 	assert.Equal(t, 0, n)
 	assert.False(t, done)
 }
+
+func TestHeadersMultipleValues(t *testing.T) {
+	// Test: Repeated fields keep every value in order
+	h := http.NewHeaders()
+	data := []byte("Set-Cookie: a=1; Expires=Wed, 21 Oct 2026 07:28:00 GMT\r\nSet-Cookie: b=2\r\n\r\n")
+	n, done, err := h.Parse(data)
+	require.NoError(t, err)
+	assert.False(t, done)
+	_, _, err = h.Parse(data[n:])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a=1; Expires=Wed, 21 Oct 2026 07:28:00 GMT", "b=2"}, h.Values("set-cookie"))
+
+	// Test: Add appends and Set replaces
+	h = http.NewHeaders()
+	h.Add("Vary", "Accept")
+	h.Add("vary", "Accept-Encoding")
+	assert.Equal(t, []string{"Accept", "Accept-Encoding"}, h.Values("VARY"))
+	h.Set("Vary", "Origin")
+	assert.Equal(t, []string{"Origin"}, h.Values("vary"))
+
+	// Test: Range visits each value separately
+	h = http.NewHeaders()
+	h.Add("Set-Cookie", "a=1")
+	h.Add("Set-Cookie", "b=2")
+	values := []string{}
+	h.Range(func(key, value string) bool {
+		values = append(values, key+": "+value)
+		return true
+	})
+	assert.Equal(t, []string{"Set-Cookie: a=1", "Set-Cookie: b=2"}, values)
+
+	// Test: Missing field
+	assert.Nil(t, h.Values("cookie"))
+	assert.Equal(t, "", h.Get("cookie"))
+}
+
+func TestHeadersOrder(t *testing.T) {
+	// Test: Parsed fields keep their casing and order
+	h := http.NewHeaders()
+	data := []byte("X-Request-ID: 7\r\nhost: localhost\r\nACCEPT: */*\r\n\r\n")
+	for done := false; !done; {
+		n, d, err := h.Parse(data)
+		require.NoError(t, err)
+		data = data[n:]
+		done = d
+	}
+	fields := []string{}
+	h.Range(func(key, value string) bool {
+		fields = append(fields, key+": "+value)
+		return true
+	})
+	assert.Equal(t, []string{"X-Request-ID: 7", "host: localhost", "ACCEPT: */*"}, fields)
+	assert.Equal(t, "localhost", h.Get("Host"))
+
+	// Test: Set replaces the first field in place and drops later duplicates
+	h = http.NewHeaders()
+	h.Add("Vary", "Accept")
+	h.Add("Content-Type", "text/plain")
+	h.Add("vary", "Origin")
+	h.Set("VARY", "*")
+	fields = []string{}
+	h.Range(func(key, value string) bool {
+		fields = append(fields, key+": "+value)
+		return true
+	})
+	assert.Equal(t, []string{"VARY: *", "Content-Type: text/plain"}, fields)
+
+	// Test: Response headers are written in insertion order
+	var buf bytes.Buffer
+	w := http.NewResponseWriter(&buf)
+	require.NoError(t, w.WriteStatusLine(http.StatusOK))
+	h = http.NewHeaders()
+	h.Set("Content-Type", "text/plain")
+	h.Set("X-Trace-Id", "abc")
+	h.Set("Content-Length", "0")
+	require.NoError(t, w.WriteHeaders(h))
+	assert.Equal(t, "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nX-Trace-Id: abc\r\nContent-Length: 0\r\nConnection: close\r\n\r\n", buf.String())
+}
+
+func TestHeadersValidation(t *testing.T) {
+	// Test: CRLF in a value is rejected and nothing is stored
+	h := http.NewHeaders()
+	err := h.Set("Location", "/home\r\nSet-Cookie: session=evil")
+	require.ErrorIs(t, err, http.ErrInvalidHeaderValue)
+	assert.Nil(t, h.Values("Location"))
+	assert.Nil(t, h.Values("Set-Cookie"))
+
+	// Test: Bare LF, NUL and DEL are rejected by Add
+	for _, value := range []string{"a\nb", "a\x00b", "a\x7fb"} {
+		assert.ErrorIs(t, h.Add("X-Value", value), http.ErrInvalidHeaderValue)
+	}
+
+	// Test: Invalid field names are rejected
+	assert.ErrorIs(t, h.Set("X Value", "a"), http.ErrInvalidHeaderName)
+	assert.ErrorIs(t, h.Set("X-Value\r\n", "a"), http.ErrInvalidHeaderName)
+	assert.ErrorIs(t, h.Add("", "a"), http.ErrInvalidHeaderName)
+
+	// Test: Tabs and obs-text are allowed
+	require.NoError(t, h.Set("X-Value", "a\tb \xe9"))
+	assert.Equal(t, "a\tb \xe9", h.Get("x-value"))
+
+	// Test: Parse rejects control characters in inbound values
+	h = http.NewHeaders()
+	n, done, err := h.Parse([]byte("X-Value: a\x01b\r\n\r\n"))
+	require.ErrorIs(t, err, http.ErrInvalidHeaderValue)
+	assert.Equal(t, 0, n)
+	assert.False(t, done)
+
+	// Test: Rejected values never reach the response
+	var buf bytes.Buffer
+	w := http.NewResponseWriter(&buf)
+	require.NoError(t, w.WriteStatusLine(http.StatusFound))
+	h = http.NewHeaders()
+	h.Set("Location", "/\r\n\r\n<html>")
+	require.NoError(t, w.WriteHeaders(h))
+	assert.Equal(t, "HTTP/1.1 302 Found\r\nConnection: close\r\n\r\n", buf.String())
+
+	// Test: An invalid default Content-Type is logged instead of dropped silently
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	h = http.GetDefaultResponseHeaders("text/plain\r\nX-Injected: 1", 0)
+	assert.Equal(t, "", h.Get("Content-Type"))
+	assert.Equal(t, "0", h.Get("Content-Length"))
+	assert.Contains(t, logs.String(), "invalid header field value")
+}
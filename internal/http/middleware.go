@@ -0,0 +1,43 @@
+package http
+
+import (
+	"log"
+	"runtime/debug"
+)
+
+type Middleware func(next Handler) Handler
+
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Handler) Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+func LogRequests(next Handler) Handler {
+	return func(w *ResponseWriter, req *Request) {
+		log.Printf("%s %s", req.RequestLine.Method, req.RequestLine.RequestTarget)
+		next(w, req)
+	}
+}
+
+func Recover(next Handler) Handler {
+	return func(w *ResponseWriter, req *Request) {
+		header := w.header.clone()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in handler: %v\n%s", r, debug.Stack())
+				if w.reset(header) {
+					w.keepAlive = false
+					writeStatusResponse(w, StatusInternalServerError)
+				} else {
+					w.abort()
+				}
+			}
+		}()
+
+		next(w, req)
+	}
+}
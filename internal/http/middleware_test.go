@@ -0,0 +1,138 @@
+package http_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/debobrad579/httpfromtcp/internal/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func tagMiddleware(tag string, calls *[]string) http.Middleware {
+	return func(next http.Handler) http.Handler {
+		return func(w *http.ResponseWriter, req *http.Request) {
+			*calls = append(*calls, tag)
+			next(w, req)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	// Test: Middleware runs in the order it is listed
+	calls := []string{}
+	handler := http.Chain(tagMiddleware("a", &calls), tagMiddleware("b", &calls))(textHandler(func(req *http.Request) string {
+		calls = append(calls, "handler")
+		return "ok"
+	}))
+	serveRequest(t, handler, "GET", "/")
+	assert.Equal(t, []string{"a", "b", "handler"}, calls)
+
+	// Test: Empty chain returns the handler unchanged
+	res := serveRequest(t, http.Chain()(textHandler(func(req *http.Request) string { return "plain" })), "GET", "/")
+	assert.True(t, strings.HasSuffix(res, "plain"))
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	calls := []string{}
+	ok := textHandler(func(req *http.Request) string { return "ok" })
+
+	router := http.NewRouter()
+	router.Use(tagMiddleware("global", &calls))
+	router.Handle("GET", "/public", ok)
+	router.Handle("GET", "/route", ok, tagMiddleware("route", &calls))
+
+	api := router.Group("/api", tagMiddleware("api", &calls))
+	api.Handle("GET", "/users", ok)
+	admin := api.Group("/admin")
+	admin.Use(tagMiddleware("admin", &calls))
+	admin.Handle("GET", "/stats", ok, tagMiddleware("stats", &calls))
+
+	// Test: Global middleware only
+	serveRequest(t, router.Serve, "GET", "/public")
+	assert.Equal(t, []string{"global"}, calls)
+
+	// Test: Per-route middleware
+	calls = calls[:0]
+	serveRequest(t, router.Serve, "GET", "/route")
+	assert.Equal(t, []string{"global", "route"}, calls)
+
+	// Test: Group middleware and prefix
+	calls = calls[:0]
+	res := serveRequest(t, router.Serve, "GET", "/api/users")
+	assert.True(t, strings.HasSuffix(res, "ok"))
+	assert.Equal(t, []string{"global", "api"}, calls)
+
+	// Test: Nested groups apply outer group middleware first
+	calls = calls[:0]
+	serveRequest(t, router.Serve, "GET", "/api/admin/stats")
+	assert.Equal(t, []string{"global", "api", "admin", "stats"}, calls)
+
+	// Test: Global middleware also sees unmatched requests
+	calls = calls[:0]
+	res = serveRequest(t, router.Serve, "GET", "/missing")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 404 Not Found\r\n"))
+	assert.Equal(t, []string{"global"}, calls)
+}
+
+func TestRecover(t *testing.T) {
+	// Test: Panics become a 500 response
+	res := serveRequest(t, http.Recover(func(w *http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	}), "GET", "/")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 500 Internal Server Error\r\n"))
+
+	// Test: Headers set by the panicking handler are dropped from the 500 response
+	res = serveRequest(t, func(w *http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Frame-Options", "DENY")
+		http.Recover(func(w *http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Set-Cookie", "session=abc")
+			panic("boom")
+		})(w, req)
+	}, "GET", "/")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 500 Internal Server Error\r\n"))
+	assert.Contains(t, res, "X-Frame-Options: DENY\r\n")
+	assert.NotContains(t, res, "Set-Cookie")
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	setHeader := func(name, value string) http.Middleware {
+		return func(next http.Handler) http.Handler {
+			return func(w *http.ResponseWriter, req *http.Request) {
+				w.Header().Set(name, value)
+				next(w, req)
+			}
+		}
+	}
+	ok := textHandler(func(req *http.Request) string { return "ok" })
+
+	// Test: Middleware adds headers before the handler commits
+	res := serveRequest(t, http.Chain(setHeader("X-Frame-Options", "DENY"), setHeader("X-Request-Id", "42"))(ok), "GET", "/")
+	assert.Contains(t, res, "X-Frame-Options: DENY\r\nX-Request-Id: 42\r\nContent-Type: text/plain\r\n")
+
+	// Test: Headers passed to WriteHeaders replace fields with the same name
+	res = serveRequest(t, setHeader("content-type", "text/html")(ok), "GET", "/")
+	assert.Contains(t, res, "Content-Type: text/plain\r\n")
+	assert.NotContains(t, res, "text/html")
+
+	// Test: Changes after the status line is written are ignored
+	res = serveRequest(t, func(w *http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Early", "1")
+		w.WriteStatusLine(http.StatusOK)
+		w.Header().Set("X-Late", "1")
+		w.WriteHeaders(http.GetDefaultResponseHeaders("text/plain", 0))
+	}, "GET", "/")
+	assert.Contains(t, res, "X-Early: 1\r\n")
+	assert.NotContains(t, res, "X-Late")
+
+	// Test: Handlers can respond with Header and Write alone
+	res = serveRequest(t, func(w *http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "2")
+		io.WriteString(w, "{}")
+		w.Header().Set("X-Late", "1")
+	}, "GET", "/")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 2\r\n"))
+	assert.True(t, strings.HasSuffix(res, "\r\n\r\n{}"))
+	assert.NotContains(t, res, "X-Late")
+}
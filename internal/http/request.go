@@ -1,82 +1,226 @@
 package http
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"io"
-	"strconv"
 )
 
-const bufferSize = 1024
-const maxBufferSize = 8 * 1024 * 1024
+const (
+	bufferSize    = 1024
+	maxBufferSize = 8 * 1024 * 1024
+)
 
 type requestState int
 
 const (
 	requestInitialized requestState = iota
 	requestParsingHeaders
-	requestParsingBody
 	requestDone
 )
 
 type Request struct {
 	RequestLine RequestLine
+	URL         *URL
 	Headers     Headers
-	Body        []byte
+	Body        io.ReadCloser
+	Trailers    *Headers
+	TLS         *tls.ConnectionState
 	state       requestState
+	bodyBytes   []byte
+	ctx         context.Context
+	pathParams  map[string]string
+}
+
+type RequestReader struct {
+	MaxRequestLineBytes int
+	MaxHeaderBytes      int
+	MaxHeaderFields     int
+	MaxBodyBytes        int64
+	reader              io.Reader
+	buf                 []byte
+	readToIndex         int
+	body                *body
+}
+
+func NewRequestReader(reader io.Reader) *RequestReader {
+	return &RequestReader{
+		MaxRequestLineBytes: defaultMaxRequestLineBytes,
+		MaxHeaderBytes:      defaultMaxHeaderBytes,
+		MaxHeaderFields:     defaultMaxHeaderFields,
+		MaxBodyBytes:        defaultMaxBodyBytes,
+		reader:              reader,
+		buf:                 make([]byte, bufferSize),
+	}
 }
 
 func RequestFromReader(reader io.Reader) (*Request, error) {
-	buf := make([]byte, bufferSize)
-	readToIndex := 0
+	return NewRequestReader(reader).ReadRequest()
+}
+
+func (rr *RequestReader) ReadRequest() (*Request, error) {
+	if err := rr.discardBody(); err != nil {
+		return nil, err
+	}
 
 	request := &Request{state: requestInitialized}
 	request.Headers = *NewHeaders()
+	request.Trailers = NewHeaders()
+	requestLineBytes, headerBytes := 0, 0
 
 	for request.state != requestDone {
-		if readToIndex >= len(buf) {
-			if len(buf)*2 > maxBufferSize {
-				return nil, errors.New("request too large")
+		state := request.state
+		n, err := request.parseSingle(rr.buffered())
+		if err != nil {
+			return nil, asParseError(err, StatusBadRequest)
+		}
+
+		rr.discard(n)
+		if state == requestInitialized {
+			requestLineBytes += n
+		} else {
+			headerBytes += n
+		}
+
+		pendingLineBytes, pendingHeaderBytes := 0, 0
+		if request.state != requestDone && n == 0 {
+			if state == requestInitialized {
+				pendingLineBytes = rr.readToIndex
+			} else {
+				pendingHeaderBytes = rr.readToIndex
 			}
-			newBuf := make([]byte, len(buf)*2)
-			copy(newBuf, buf)
-			buf = newBuf
 		}
 
-		nRead, err := reader.Read(buf[readToIndex:])
-		if err != nil {
+		if err := rr.checkHeaderLimits(requestLineBytes+pendingLineBytes, headerBytes+pendingHeaderBytes, request.Headers.Len()); err != nil {
 			return nil, err
 		}
 
-		readToIndex += nRead
+		if request.state == requestDone || n > 0 {
+			continue
+		}
 
-		nParsed, err := request.parse(buf[:readToIndex])
-		if err != nil {
+		if err := rr.fill(); err != nil {
+			if errors.Is(err, io.EOF) && (request.state != requestInitialized || rr.readToIndex > 0) {
+				return nil, io.ErrUnexpectedEOF
+			}
 			return nil, err
 		}
+	}
 
-		copy(buf, buf[nParsed:readToIndex])
-		readToIndex -= nParsed
+	body, err := newBody(rr, request)
+	if err != nil {
+		return nil, err
 	}
 
+	rr.body = body
+	request.Body = body
 	return request, nil
 }
 
-func (r *Request) parse(data []byte) (int, error) {
-	totalBytesParsed := 0
+func (rr *RequestReader) discardBody() error {
+	if rr.body == nil {
+		return nil
+	}
 
-	for r.state != requestDone {
-		n, err := r.parseSingle(data[totalBytesParsed:])
-		if err != nil {
-			return n, err
+	if err := rr.body.drain(); err != nil {
+		return err
+	}
+
+	rr.body = nil
+	return nil
+}
+
+func (rr *RequestReader) checkHeaderLimits(requestLineBytes, headerBytes, headerFields int) error {
+	if exceedsLimit(requestLineBytes, rr.MaxRequestLineBytes) {
+		return newParseError(StatusRequestURITooLong, "request line too long")
+	}
+	if exceedsLimit(headerBytes, rr.MaxHeaderBytes) {
+		return newParseError(StatusRequestHeaderFieldsTooLarge, "request header too large")
+	}
+	if exceedsLimit(headerFields, rr.MaxHeaderFields) {
+		return newParseError(StatusRequestHeaderFieldsTooLarge, "too many header fields")
+	}
+
+	return nil
+}
+
+func exceedsLimit(size, limit int) bool {
+	return limit > 0 && size > limit
+}
+
+func (rr *RequestReader) peek() error {
+	if rr.readToIndex > 0 {
+		return nil
+	}
+
+	return rr.fill()
+}
+
+func (rr *RequestReader) buffered() []byte {
+	return rr.buf[:rr.readToIndex]
+}
+
+func (rr *RequestReader) discard(n int) {
+	copy(rr.buf, rr.buf[n:rr.readToIndex])
+	rr.readToIndex -= n
+}
+
+func (rr *RequestReader) fill() error {
+	if rr.readToIndex >= len(rr.buf) {
+		limit := maxBufferSize
+		if rr.MaxRequestLineBytes > 0 && rr.MaxHeaderBytes > 0 {
+			limit = min(limit, max(rr.MaxRequestLineBytes, rr.MaxHeaderBytes))
 		}
-		if n == 0 {
-			return totalBytesParsed, nil
+		if len(rr.buf) >= limit {
+			return newParseError(StatusBadRequest, "line too long")
 		}
+		newBuf := make([]byte, len(rr.buf)*2)
+		copy(newBuf, rr.buf)
+		rr.buf = newBuf
+	}
 
-		totalBytesParsed += n
+	nRead, err := rr.reader.Read(rr.buf[rr.readToIndex:])
+	rr.readToIndex += nRead
+
+	if nRead > 0 {
+		return nil
 	}
 
-	return totalBytesParsed, nil
+	return err
+}
+
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+
+	return r.ctx
+}
+
+func (r *Request) WithContext(ctx context.Context) *Request {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	r2 := new(Request)
+	*r2 = *r
+	r2.ctx = ctx
+	return r2
+}
+
+func (r *Request) BodyBytes() ([]byte, error) {
+	if r.bodyBytes != nil || r.Body == nil {
+		return r.bodyBytes, nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.bodyBytes = data
+	return r.bodyBytes, nil
 }
 
 func (r *Request) parseSingle(data []byte) (int, error) {
@@ -91,7 +235,13 @@ func (r *Request) parseSingle(data []byte) (int, error) {
 			return 0, nil
 		}
 
+		url, err := parseRequestTarget(requestLine.RequestTarget, requestLine.TargetForm)
+		if err != nil {
+			return 0, err
+		}
+
 		r.RequestLine = *requestLine
+		r.URL = url
 		r.state = requestParsingHeaders
 		return n, nil
 	case requestParsingHeaders:
@@ -101,39 +251,10 @@ func (r *Request) parseSingle(data []byte) (int, error) {
 		}
 
 		if done {
-			r.state = requestParsingBody
-		}
-
-		return n, nil
-	case requestParsingBody:
-		contentLengthStr := r.Headers.Get("Content-Length")
-		if contentLengthStr == "" {
-			r.state = requestDone
-			return 0, nil
-		}
-
-		contentLength, err := strconv.Atoi(contentLengthStr)
-		if err != nil {
-			return 0, err
-		}
-
-		if contentLength == 0 {
-			r.state = requestDone
-			return 0, nil
-		}
-
-		remaining := contentLength - len(r.Body)
-		if len(data) > remaining {
-			data = data[:remaining]
-		}
-
-		r.Body = append(r.Body, data...)
-
-		if len(r.Body) == contentLength {
 			r.state = requestDone
 		}
 
-		return len(data), nil
+		return n, nil
 	case requestDone:
 		return 0, errors.New("trying to read data in a done state")
 	default:
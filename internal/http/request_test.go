@@ -1,7 +1,10 @@
 package http_test
 
 import (
+	"context"
+	"errors"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/debobrad579/httpfromtcp/internal/http"
@@ -27,6 +30,13 @@ func (cr *chunkReader) Read(p []byte) (n int, err error) {
 	return n, nil
 }
 
+func readBody(t *testing.T, r *http.Request) string {
+	t.Helper()
+	body, err := r.BodyBytes()
+	require.NoError(t, err)
+	return string(body)
+}
+
 func TestHeadersParse(t *testing.T) {
 	// Test: Standard headers
 	reader := &chunkReader{
@@ -88,7 +98,7 @@ func TestBodyParse(t *testing.T) {
 	r, err := http.RequestFromReader(reader)
 	require.NoError(t, err)
 	require.NotNil(t, r)
-	assert.Equal(t, "hello world!\n", string(r.Body))
+	assert.Equal(t, "hello world!\n", readBody(t, r))
 
 	// Test: Body shorter than reported content length
 	reader = &chunkReader{
@@ -100,6 +110,8 @@ func TestBodyParse(t *testing.T) {
 		numBytesPerRead: 3,
 	}
 	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	_, err = r.BodyBytes()
 	require.Error(t, err)
 
 	// Test: Empty body, 0 reported content length
@@ -113,7 +125,7 @@ func TestBodyParse(t *testing.T) {
 	r, err = http.RequestFromReader(reader)
 	require.NoError(t, err)
 	require.NotNil(t, r)
-	assert.Equal(t, "", string(r.Body))
+	assert.Equal(t, "", readBody(t, r))
 
 	// Test: Empty body, no reported content length
 	reader = &chunkReader{
@@ -125,7 +137,7 @@ func TestBodyParse(t *testing.T) {
 	r, err = http.RequestFromReader(reader)
 	require.NoError(t, err)
 	require.NotNil(t, r)
-	assert.Equal(t, "", string(r.Body))
+	assert.Equal(t, "", readBody(t, r))
 
 	// Test: No Content-Length but body exists (we're assuming Content-Length will be present if a body exists)
 	reader = &chunkReader{
@@ -138,5 +150,565 @@ func TestBodyParse(t *testing.T) {
 	r, err = http.RequestFromReader(reader)
 	require.NoError(t, err)
 	require.NotNil(t, r)
-	assert.Equal(t, "", string(r.Body))
+	assert.Equal(t, "", readBody(t, r))
+}
+
+func TestPipelinedRequests(t *testing.T) {
+	// Test: Two pipelined requests read from the same connection
+	reader := &chunkReader{
+		data: "POST /first HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Content-Length: 5\r\n" +
+			"\r\n" +
+			"hello" +
+			"GET /second HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"\r\n",
+		numBytesPerRead: 1024,
+	}
+	reqReader := http.NewRequestReader(reader)
+	r, err := reqReader.ReadRequest()
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "/first", r.RequestLine.RequestTarget)
+	assert.Equal(t, "hello", readBody(t, r))
+
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "/second", r.RequestLine.RequestTarget)
+	assert.Equal(t, "", readBody(t, r))
+
+	_, err = reqReader.ReadRequest()
+	require.ErrorIs(t, err, io.EOF)
+
+	// Test: Pipelined requests split across small reads
+	reader = &chunkReader{
+		data: "GET /a HTTP/1.1\r\nHost: localhost:42069\r\n\r\n" +
+			"GET /b HTTP/1.1\r\nHost: localhost:42069\r\n\r\n" +
+			"GET /c HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 7,
+	}
+	reqReader = http.NewRequestReader(reader)
+	for _, target := range []string{"/a", "/b", "/c"} {
+		r, err = reqReader.ReadRequest()
+		require.NoError(t, err)
+		assert.Equal(t, target, r.RequestLine.RequestTarget)
+	}
+
+	// Test: Connection closed in the middle of a request
+	reader = &chunkReader{
+		data:            "GET /a HTTP/1.1\r\nHost: localhost:42069\r\n\r\nGET /b HTTP/1.1\r\n",
+		numBytesPerRead: 1024,
+	}
+	reqReader = http.NewRequestReader(reader)
+	_, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	_, err = reqReader.ReadRequest()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// Test: Unread body is skipped before the next request
+	reader = &chunkReader{
+		data: "POST /first HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"5\r\nhello\r\n0\r\n\r\n" +
+			"GET /second HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"\r\n",
+		numBytesPerRead: 4,
+	}
+	reqReader = http.NewRequestReader(reader)
+	_, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "/second", r.RequestLine.RequestTarget)
+}
+
+func TestStreamingBody(t *testing.T) {
+	// Test: Body is read incrementally
+	reader := &chunkReader{
+		data: "POST /upload HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Content-Length: 11\r\n" +
+			"\r\n" +
+			"hello world",
+		numBytesPerRead: 3,
+	}
+	r, err := http.RequestFromReader(reader)
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	n, err := io.ReadFull(r.Body, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hell", string(buf[:n]))
+	rest, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "o world", string(rest))
+
+	// Test: Reading a closed body fails
+	reader = &chunkReader{
+		data: "POST /upload HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Content-Length: 5\r\n" +
+			"\r\n" +
+			"hello",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	require.NoError(t, r.Body.Close())
+	_, err = r.Body.Read(buf)
+	require.Error(t, err)
+}
+
+func TestChunkedBodyParse(t *testing.T) {
+	// Test: Standard chunked body
+	reader := &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"5\r\nhello\r\n" +
+			"7\r\n world!\r\n" +
+			"0\r\n" +
+			"\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err := http.RequestFromReader(reader)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "hello world!", readBody(t, r))
+
+	// Test: Chunk extensions and uppercase hex sizes
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"A;name=value\r\n0123456789\r\n" +
+			"3 ; quoted=\"a b\";flag\r\nabc\r\n" +
+			"0;last\r\n" +
+			"\r\n",
+		numBytesPerRead: 4,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "0123456789abc", readBody(t, r))
+
+	// Test: Quoted extension values may contain ";" and escaped quotes
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"3;name=\"a;b\"\r\nabc\r\n" +
+			"2;name=\"say \\\"hi\\\"\";flag\r\nde\r\n" +
+			"0\r\n" +
+			"\r\n",
+		numBytesPerRead: 4,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "abcde", readBody(t, r))
+
+	for _, extension := range []string{`name="a"b"`, `name=a"b`, `name="ab`, `name="a;b`, "name=", ";"} {
+		// Test: Malformed quoted strings and empty extensions are rejected
+		reader = &chunkReader{
+			data: "POST /submit HTTP/1.1\r\n" +
+				"Host: localhost:42069\r\n" +
+				"Transfer-Encoding: chunked\r\n" +
+				"\r\n" +
+				"3;" + extension + "\r\nabc\r\n" +
+				"0\r\n" +
+				"\r\n",
+			numBytesPerRead: 4,
+		}
+		r, err = http.RequestFromReader(reader)
+		require.NoError(t, err)
+		_, err = r.BodyBytes()
+		require.Error(t, err, extension)
+	}
+
+	// Test: Trailers
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"Trailer: X-Checksum\r\n" +
+			"\r\n" +
+			"5\r\nhello\r\n" +
+			"0\r\n" +
+			"X-Checksum: abc123\r\n" +
+			"\r\n",
+		numBytesPerRead: 5,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "hello", readBody(t, r))
+	assert.Equal(t, "abc123", r.Trailers.Get("x-checksum"))
+	assert.Equal(t, "", r.Headers.Get("x-checksum"))
+
+	// Test: Chunked body followed by a pipelined request
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"5\r\nhello\r\n" +
+			"0\r\n" +
+			"\r\n" +
+			"GET /next HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"\r\n",
+		numBytesPerRead: 1024,
+	}
+	reqReader := http.NewRequestReader(reader)
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", readBody(t, r))
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "/next", r.RequestLine.RequestTarget)
+
+	// Test: Invalid chunk size
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"xyz\r\nhello\r\n" +
+			"0\r\n" +
+			"\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	_, err = r.BodyBytes()
+	require.Error(t, err)
+
+	// Test: Chunk data longer than chunk size
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"3\r\nhello\r\n" +
+			"0\r\n" +
+			"\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	_, err = r.BodyBytes()
+	require.Error(t, err)
+
+	// Test: Missing last chunk
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"5\r\nhello\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	_, err = r.BodyBytes()
+	require.Error(t, err)
+
+	// Test: Unsupported transfer encoding
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: gzip\r\n" +
+			"\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+}
+
+func TestRequestLimits(t *testing.T) {
+	// Test: Headers larger than the limit
+	reader := &chunkReader{
+		data: "GET / HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"X-Padding: " + strings.Repeat("a", 100) + "\r\n" +
+			"\r\n",
+		numBytesPerRead: 8,
+	}
+	reqReader := http.NewRequestReader(reader)
+	reqReader.MaxHeaderBytes = 64
+	_, err := reqReader.ReadRequest()
+	require.Error(t, err)
+
+	// Test: Content-Length larger than the body limit
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Content-Length: 13\r\n" +
+			"\r\n" +
+			"hello world!\n",
+		numBytesPerRead: 3,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxBodyBytes = 10
+	_, err = reqReader.ReadRequest()
+	require.Error(t, err)
+
+	// Test: Chunked body growing past the body limit
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"6\r\nhello \r\n" +
+			"6\r\nworld!\r\n" +
+			"0\r\n" +
+			"\r\n",
+		numBytesPerRead: 3,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxBodyBytes = 10
+	r, err := reqReader.ReadRequest()
+	require.NoError(t, err)
+	_, err = r.BodyBytes()
+	require.Error(t, err)
+
+	// Test: The request line does not count towards the header limit
+	reader = &chunkReader{
+		data:            "GET /" + strings.Repeat("a", 100) + " HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 1024,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxHeaderBytes = 64
+	reqReader.MaxRequestLineBytes = 128
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:42069", r.Headers.Get("host"))
+
+	// Test: Headers within the limit are accepted across small reads
+	reader = &chunkReader{
+		data:            "GET / HTTP/1.1\r\nHost: localhost:42069\r\nAccept: */*\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxRequestLineBytes = 16
+	reqReader.MaxHeaderBytes = 40
+	reqReader.MaxHeaderFields = 2
+	_, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+
+	// Test: Header limits are enforced before the whole header is read
+	reader = &chunkReader{
+		data:            "GET / HTTP/1.1\r\n" + strings.Repeat("X-Field: value\r\n", 10000) + "\r\n",
+		numBytesPerRead: 64,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxHeaderFields = 10
+	_, err = reqReader.ReadRequest()
+	var parseErr *http.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, parseErr.StatusCode)
+	assert.Less(t, reader.pos, 1024)
+
+	// Test: Chunk sizes near MaxInt64 cannot overflow the body limit
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"1\r\na\r\n" +
+			"7fffffffffffffff\r\n" + strings.Repeat("a", 20000),
+		numBytesPerRead: 1024,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxBodyBytes = 10
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	_, err = r.BodyBytes()
+	require.ErrorAs(t, err, &parseErr)
+	assert.Less(t, reader.pos, len(reader.data))
+
+	// Test: A large chunk after a small one trips the body limit
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"1\r\na\r\n" +
+			"7fffffff\r\n",
+		numBytesPerRead: 1024,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxBodyBytes = 10
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	_, err = r.BodyBytes()
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, parseErr.StatusCode)
+
+	// Test: Oversized chunk sizes are rejected even without a body limit
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Transfer-Encoding: chunked\r\n" +
+			"\r\n" +
+			"10000000000000000\r\n",
+		numBytesPerRead: 1024,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxBodyBytes = -1
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	_, err = r.BodyBytes()
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, http.StatusBadRequest, parseErr.StatusCode)
+
+	// Test: Buffer growth stays bounded when the header limits are disabled
+	reader = &chunkReader{
+		data:            "GET /" + strings.Repeat("a", 9*1024*1024) + " HTTP/1.1\r\n\r\n",
+		numBytesPerRead: 64 * 1024,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxRequestLineBytes = -1
+	reqReader.MaxHeaderBytes = -1
+	_, err = reqReader.ReadRequest()
+	require.Error(t, err)
+	assert.Less(t, reader.pos, len(reader.data))
+
+	// Test: Negative limits disable the checks
+	reader = &chunkReader{
+		data: "POST /submit HTTP/1.1\r\n" +
+			"Host: localhost:42069\r\n" +
+			"Content-Length: 13\r\n" +
+			"\r\n" +
+			"hello world!\n",
+		numBytesPerRead: 3,
+	}
+	reqReader = http.NewRequestReader(reader)
+	reqReader.MaxHeaderBytes = -1
+	reqReader.MaxBodyBytes = -1
+	r, err = reqReader.ReadRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "hello world!\n", readBody(t, r))
+}
+
+func TestRequestContext(t *testing.T) {
+	// Test: Requests read outside a server default to a background context
+	reader := &chunkReader{
+		data:            "GET / HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err := http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, context.Background(), r.Context())
+
+	// Test: WithContext returns a copy carrying the new context
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	r2 := r.WithContext(ctx)
+	assert.Equal(t, "value", r2.Context().Value(ctxKey{}))
+	assert.Nil(t, r.Context().Value(ctxKey{}))
+	assert.Equal(t, r.RequestLine, r2.RequestLine)
+
+	// Test: Trailers read through a derived request reach both copies
+	reader = &chunkReader{
+		data: "POST / HTTP/1.1\r\nHost: localhost:42069\r\nTransfer-Encoding: chunked\r\n\r\n" +
+			"5\r\nhello\r\n0\r\nX-Sum: 9\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	r2 = r.WithContext(ctx)
+	_, err = io.ReadAll(r2.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "9", r2.Trailers.Get("X-Sum"))
+	assert.Equal(t, "9", r.Trailers.Get("X-Sum"))
+}
+
+func TestParseErrorStatusCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		configure  func(reqReader *http.RequestReader)
+		statusCode http.StatusCode
+	}{
+		{
+			name:       "Malformed request line",
+			data:       "GET /\r\nHost: localhost:42069\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Malformed header",
+			data:       "GET / HTTP/1.1\r\nHost localhost:42069\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Control character in header value",
+			data:       "GET / HTTP/1.1\r\nX-Name: a\x00b\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Malformed percent-encoding",
+			data:       "GET /%zz HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Unsupported version",
+			data:       "GET / HTTP/3.0\r\nHost: localhost:42069\r\n\r\n",
+			statusCode: http.StatusHTTPVersionNotSupported,
+		},
+		{
+			name:       "Request line too long",
+			data:       "GET /" + strings.Repeat("a", 100) + " HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+			configure:  func(reqReader *http.RequestReader) { reqReader.MaxRequestLineBytes = 64 },
+			statusCode: http.StatusRequestURITooLong,
+		},
+		{
+			name:       "Headers too large",
+			data:       "GET / HTTP/1.1\r\nX-Padding: " + strings.Repeat("a", 100) + "\r\n\r\n",
+			configure:  func(reqReader *http.RequestReader) { reqReader.MaxHeaderBytes = 64 },
+			statusCode: http.StatusRequestHeaderFieldsTooLarge,
+		},
+		{
+			name:       "Too many header fields",
+			data:       "GET / HTTP/1.1\r\nA: 1\r\nB: 2\r\nC: 3\r\n\r\n",
+			configure:  func(reqReader *http.RequestReader) { reqReader.MaxHeaderFields = 2 },
+			statusCode: http.StatusRequestHeaderFieldsTooLarge,
+		},
+		{
+			name:       "Body too large",
+			data:       "POST / HTTP/1.1\r\nContent-Length: 100\r\n\r\n",
+			configure:  func(reqReader *http.RequestReader) { reqReader.MaxBodyBytes = 10 },
+			statusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "Unknown transfer coding",
+			data:       "POST / HTTP/1.1\r\nTransfer-Encoding: compress\r\n\r\n",
+			statusCode: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tc := range tests {
+		// Test: Parse errors carry a suggested status code
+		reqReader := http.NewRequestReader(&chunkReader{data: tc.data, numBytesPerRead: 8})
+		if tc.configure != nil {
+			tc.configure(reqReader)
+		}
+		_, err := reqReader.ReadRequest()
+		var parseErr *http.ParseError
+		require.ErrorAs(t, err, &parseErr, tc.name)
+		assert.Equal(t, tc.statusCode, parseErr.StatusCode, tc.name)
+	}
+
+	// Test: Connection errors are not parse errors
+	_, err := http.RequestFromReader(&chunkReader{data: "GET / HTTP/1.1\r\n", numBytesPerRead: 8})
+	var parseErr *http.ParseError
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &parseErr))
 }
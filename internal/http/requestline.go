@@ -8,10 +8,20 @@ import (
 	"unicode"
 )
 
+type RequestTargetForm int
+
+const (
+	OriginForm RequestTargetForm = iota
+	AbsoluteForm
+	AuthorityForm
+	AsteriskForm
+)
+
 type RequestLine struct {
 	HttpVersion   string
 	RequestTarget string
 	Method        string
+	TargetForm    RequestTargetForm
 }
 
 func parseRequestLine(data []byte) (*RequestLine, int, error) {
@@ -36,22 +46,63 @@ func parseRequestLine(data []byte) (*RequestLine, int, error) {
 	}
 
 	reqTarget := parts[1]
-
-	if reqTarget == "" || !strings.HasPrefix(reqTarget, "/") {
-		return nil, 0, fmt.Errorf("invalid request target format")
+	targetForm, err := requestTargetForm(method, reqTarget)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if !strings.HasPrefix(parts[2], "HTTP/") {
+	httpVersion, found := strings.CutPrefix(parts[2], "HTTP/")
+	if !found || !isValidVersionFormat(httpVersion) {
 		return nil, 0, fmt.Errorf("invalid http version format: %s", parts[2])
 	}
-	httpVersion := strings.TrimPrefix(parts[2], "HTTP/")
-	if httpVersion != "1.1" {
-		return nil, 0, fmt.Errorf("incorrect http version: %s", httpVersion)
+	if httpVersion != "1.1" && httpVersion != "1.0" {
+		return nil, 0, newParseError(StatusHTTPVersionNotSupported, "%w: %s", ErrHTTPVersionNotSupported, httpVersion)
 	}
 
 	return &RequestLine{
 		HttpVersion:   httpVersion,
 		RequestTarget: reqTarget,
 		Method:        method,
+		TargetForm:    targetForm,
 	}, consumed, nil
 }
+
+func isValidVersionFormat(version string) bool {
+	major, minor, hasMinor := strings.Cut(version, ".")
+	return isDigits(major) && (!hasMinor || isDigits(minor))
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func requestTargetForm(method, target string) (RequestTargetForm, error) {
+	switch {
+	case method == "CONNECT":
+		if strings.ContainsAny(target, "/?#@") || !strings.Contains(target, ":") {
+			return 0, fmt.Errorf("invalid authority-form request target: %s", target)
+		}
+		return AuthorityForm, nil
+	case target == "*":
+		if method != "OPTIONS" {
+			return 0, fmt.Errorf("asterisk-form request target not allowed for %s", method)
+		}
+		return AsteriskForm, nil
+	case strings.HasPrefix(target, "/"):
+		return OriginForm, nil
+	case strings.Contains(target, "://"):
+		return AbsoluteForm, nil
+	default:
+		return 0, fmt.Errorf("invalid request target format")
+	}
+}
@@ -83,5 +83,121 @@ func TestRequestLineParse(t *testing.T) {
 		numBytesPerRead: 3,
 	}
 	_, err = http.RequestFromReader(reader)
+	require.ErrorIs(t, err, http.ErrHTTPVersionNotSupported)
+
+	// Test: Good HTTP/1.0 Request line
+	reader = &chunkReader{
+		data:            "GET /coffee HTTP/1.0\r\nUser-Agent: ApacheBench/2.3\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", r.RequestLine.HttpVersion)
+
+	// Test: Malformed version in Request line
+	reader = &chunkReader{
+		data:            "GET / HTTP/1.x\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, http.ErrHTTPVersionNotSupported)
+
+	// Test: Asterisk-form target is only allowed for OPTIONS
+	reader = &chunkReader{
+		data:            "GET * HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+
+	// Test: Target without a leading slash or scheme
+	reader = &chunkReader{
+		data:            "GET coffee HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+}
+
+func TestRequestTargetForms(t *testing.T) {
+	// Test: Origin-form
+	reader := &chunkReader{
+		data:            "GET /coffee?size=large HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err := http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, http.OriginForm, r.RequestLine.TargetForm)
+	assert.Equal(t, "", r.URL.Scheme)
+	assert.Equal(t, "", r.URL.Host)
+	assert.Equal(t, "/coffee", r.URL.Path)
+
+	// Test: Absolute-form
+	reader = &chunkReader{
+		data:            "GET HTTP://Example.com:8080/coffee?size=large HTTP/1.1\r\nHost: example.com:8080\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, http.AbsoluteForm, r.RequestLine.TargetForm)
+	assert.Equal(t, "http", r.URL.Scheme)
+	assert.Equal(t, "example.com:8080", r.URL.Host)
+	assert.Equal(t, "/coffee", r.URL.Path)
+	assert.Equal(t, "large", r.URL.Query.Get("size"))
+
+	// Test: Absolute-form without a path
+	reader = &chunkReader{
+		data:            "GET http://example.com HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", r.URL.Host)
+	assert.Equal(t, "/", r.URL.Path)
+
+	// Test: Absolute-form with userinfo
+	reader = &chunkReader{
+		data:            "GET http://user@example.com/ HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+
+	// Test: Authority-form
+	reader = &chunkReader{
+		data:            "CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, http.AuthorityForm, r.RequestLine.TargetForm)
+	assert.Equal(t, "example.com:443", r.URL.Host)
+	assert.Equal(t, "", r.URL.Path)
+
+	// Test: Authority-form without a port
+	reader = &chunkReader{
+		data:            "CONNECT example.com HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+
+	// Test: Authority-form with a path
+	reader = &chunkReader{
+		data:            "CONNECT example.com:443/path HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
 	require.Error(t, err)
+
+	// Test: Asterisk-form
+	reader = &chunkReader{
+		data:            "OPTIONS * HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, http.AsteriskForm, r.RequestLine.TargetForm)
+	assert.Equal(t, "*", r.URL.Path)
 }
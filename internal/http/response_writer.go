@@ -1,8 +1,18 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	responseBufferSize = 4096
+	dateFormat         = "Mon, 02 Jan 2006 15:04:05 GMT"
 )
 
 type writerState int
@@ -14,55 +24,142 @@ const (
 )
 
 type ResponseWriter struct {
-	writerState writerState
-	writer      io.Writer
+	writerState      writerState
+	writer           io.Writer
+	statusCode       StatusCode
+	defaultStatus    StatusCode
+	header           *Headers
+	headers          *Headers
+	buf              []byte
+	buffered         bool
+	committed        bool
+	aborted          bool
+	keepAlive        bool
+	serverClosed     *atomic.Bool
+	reqBody          *body
+	http10           bool
+	continueSent     bool
+	unframedChunks   bool
+	chunked          bool
+	trailersDone     bool
+	contentLength    int
+	bodyBytesWritten int
 }
 
 func NewResponseWriter(writer io.Writer) *ResponseWriter {
 	return &ResponseWriter{
-		writerState: writerStateStatusLine,
-		writer:      writer,
+		writerState:   writerStateStatusLine,
+		writer:        writer,
+		header:        NewHeaders(),
+		contentLength: -1,
 	}
 }
 
+// Header returns the fields sent with the response. Changes made after
+// WriteStatusLine or the first Write have no effect.
+func (w *ResponseWriter) Header() *Headers {
+	return w.header
+}
+
 func (w *ResponseWriter) WriteStatusLine(statusCode StatusCode) error {
 	if w.writerState != writerStateStatusLine {
 		return fmt.Errorf("cannot write status line in state %d", w.writerState)
 	}
-	defer func() { w.writerState = writerStateHeaders }()
+	if statusCode >= 100 && statusCode < 200 && statusCode != StatusSwitchingProtocols {
+		return fmt.Errorf("cannot write informational status %d as a final response", statusCode)
+	}
 
-	reasonPhrase, ok := reasonPhrases[statusCode]
-	if !ok {
-		reasonPhrase = "Unknown"
+	w.statusCode = statusCode
+	w.headers = w.header.clone()
+	w.writerState = writerStateHeaders
+	return nil
+}
+
+// WriteInformational sends an interim 1xx response before the final status
+// line. HTTP/1.0 clients cannot receive them, so nothing is written there.
+func (w *ResponseWriter) WriteInformational(statusCode StatusCode, h *Headers) error {
+	if w.committed {
+		return errors.New("cannot write informational response after the final response")
+	}
+	if statusCode < 100 || statusCode >= 200 || statusCode == StatusSwitchingProtocols {
+		return fmt.Errorf("invalid informational status %d", statusCode)
 	}
 
-	if _, err := fmt.Fprintf(w.writer, "HTTP/1.1 %d %s\r\n", statusCode, reasonPhrase); err != nil {
+	if w.http10 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w.writer, "HTTP/1.1 %d %s\r\n", statusCode, reasonPhrase(statusCode)); err != nil {
 		return err
 	}
 
+	if h != nil {
+		if err := w.writeFields(h); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprint(w.writer, "\r\n"); err != nil {
+		return err
+	}
+
+	if statusCode == StatusContinue {
+		w.continueSent = true
+	}
 	return nil
 }
 
+func (w *ResponseWriter) writeContinue() error {
+	if w.committed || w.continueSent {
+		return nil
+	}
+
+	return w.WriteInformational(StatusContinue, nil)
+}
+
+// WriteHeaders sends the response head straight away when the headers frame
+// the body. Writers created by the server hold it back otherwise, until the
+// body size is known.
 func (w *ResponseWriter) WriteHeaders(h *Headers) error {
 	if w.writerState != writerStateHeaders {
 		return fmt.Errorf("cannot write headers in state %d", w.writerState)
 	}
-	defer func() { w.writerState = writerStateBody }()
+	if h != nil {
+		w.headers.merge(h)
+	}
 
-	var writeErr error
-	h.Range(func(fieldName, fieldValue string) bool {
-		if _, writeErr = fmt.Fprintf(w.writer, "%s: %s\r\n", fieldName, fieldValue); writeErr != nil {
-			return false
+	w.writerState = writerStateBody
+	if !w.buffered || !bodyAllowed(w.statusCode) || w.headers.Get("Content-Length") != "" || w.headers.Get("Transfer-Encoding") != "" {
+		return w.commit()
+	}
+
+	return nil
+}
+
+// Write buffers small bodies in server-created writers so Content-Length can
+// be set when the handler returns, and switches to chunked encoding once the
+// buffer fills up.
+func (w *ResponseWriter) Write(p []byte) (int, error) {
+	if err := w.prepareBody(); err != nil {
+		return 0, err
+	}
+	if !bodyAllowed(w.statusCode) {
+		return 0, ErrBodyNotAllowed
+	}
+
+	if !w.committed {
+		if len(w.buf)+len(p) <= responseBufferSize {
+			w.buf = append(w.buf, p...)
+			return len(p), nil
 		}
-		return true
-	})
 
-	if writeErr != nil {
-		return writeErr
+		w.headers.Set("Transfer-Encoding", "chunked")
+		if err := w.commit(); err != nil {
+			return 0, err
+		}
 	}
 
-	_, err := fmt.Fprint(w.writer, "\r\n")
-	return err
+	return w.writeBody(p)
 }
 
 func (w *ResponseWriter) WriteBody(p []byte) (int, error) {
@@ -70,29 +167,120 @@ func (w *ResponseWriter) WriteBody(p []byte) (int, error) {
 		return 0, fmt.Errorf("cannot write body in state %d", w.writerState)
 	}
 
-	return w.writer.Write(p)
+	return w.Write(p)
 }
 
 func (w *ResponseWriter) WriteChunkedBody(p []byte) (int, error) {
 	if w.writerState != writerStateBody {
 		return 0, fmt.Errorf("cannot write chunked body in state %d", w.writerState)
 	}
+	if err := w.commitChunked(); err != nil {
+		return 0, err
+	}
 
-	return fmt.Fprintf(w.writer, "%x\r\n%s\r\n", len(p), p)
+	return w.writeBody(p)
 }
 
 func (w *ResponseWriter) WriteTrailers(trailers *Headers) error {
 	if w.writerState != writerStateBody {
 		return fmt.Errorf("cannot write trailers in state %d", w.writerState)
 	}
+	if err := w.commitChunked(); err != nil {
+		return err
+	}
+
+	if w.unframedChunks {
+		return nil
+	}
+	if w.trailersDone {
+		return errors.New("trailers already written")
+	}
 
 	if _, err := fmt.Fprint(w.writer, "0\r\n"); err != nil {
 		return err
 	}
 
-	var writeErr error
+	if err := w.writeFields(trailers); err != nil {
+		return err
+	}
 
-	trailers.Range(func(fieldName, fieldValue string) bool {
+	_, err := fmt.Fprint(w.writer, "\r\n")
+	if err == nil {
+		w.trailersDone = true
+	}
+	return err
+}
+
+// prepareBody writes the default status line and headers if the handler has
+// not. The default status is 200 unless the server has set another one.
+func (w *ResponseWriter) prepareBody() error {
+	if w.writerState == writerStateStatusLine {
+		statusCode := StatusOK
+		if w.defaultStatus != 0 {
+			statusCode = w.defaultStatus
+		}
+		if err := w.WriteStatusLine(statusCode); err != nil {
+			return err
+		}
+	}
+	if w.writerState == writerStateHeaders {
+		return w.WriteHeaders(nil)
+	}
+
+	return nil
+}
+
+func (w *ResponseWriter) commitChunked() error {
+	if !w.committed {
+		w.headers.Set("Transfer-Encoding", "chunked")
+		if err := w.commit(); err != nil {
+			return err
+		}
+	}
+	if !w.chunked && !w.unframedChunks {
+		return errors.New("response is not chunked")
+	}
+
+	return nil
+}
+
+func (w *ResponseWriter) commit() error {
+	h := w.headers
+	w.committed = true
+
+	if _, err := fmt.Fprintf(w.writer, "HTTP/1.1 %d %s\r\n", w.statusCode, reasonPhrase(w.statusCode)); err != nil {
+		return err
+	}
+
+	if hasToken(h.Get("Connection"), "close") || (w.serverClosed != nil && w.serverClosed.Load()) {
+		w.keepAlive = false
+	}
+	w.chunked = hasToken(h.Get("Transfer-Encoding"), "chunked")
+	if w.chunked && w.http10 {
+		w.chunked = false
+		w.unframedChunks = true
+	}
+	if contentLength, err := strconv.Atoi(h.Get("Content-Length")); err == nil && contentLength >= 0 {
+		w.contentLength = contentLength
+	}
+	if !w.chunked && w.contentLength == -1 && bodyAllowed(w.statusCode) {
+		w.keepAlive = false
+	}
+	if w.reqBody != nil && w.reqBody.awaitingFirstRead() {
+		w.keepAlive = false
+	}
+	if w.buffered && h.Get("Date") == "" {
+		h.Set("Date", time.Now().UTC().Format(dateFormat))
+	}
+
+	var writeErr error
+	h.Range(func(fieldName, fieldValue string) bool {
+		if strings.EqualFold(fieldName, "Connection") {
+			return true
+		}
+		if w.unframedChunks && (strings.EqualFold(fieldName, "Transfer-Encoding") || strings.EqualFold(fieldName, "Trailer")) {
+			return true
+		}
 		if _, writeErr = fmt.Fprintf(w.writer, "%s: %s\r\n", fieldName, fieldValue); writeErr != nil {
 			return false
 		}
@@ -103,6 +291,129 @@ func (w *ResponseWriter) WriteTrailers(trailers *Headers) error {
 		return writeErr
 	}
 
-	_, err := fmt.Fprint(w.writer, "\r\n")
-	return err
+	connection := "close"
+	if w.keepAlive {
+		connection = "keep-alive"
+	}
+
+	if _, err := fmt.Fprintf(w.writer, "Connection: %s\r\n\r\n", connection); err != nil {
+		return err
+	}
+
+	if len(w.buf) > 0 {
+		buf := w.buf
+		w.buf = nil
+		if _, err := w.writeBody(buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *ResponseWriter) writeBody(p []byte) (int, error) {
+	if w.unframedChunks {
+		return w.writer.Write(p)
+	}
+
+	if w.chunked {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		if _, err := fmt.Fprintf(w.writer, "%x\r\n%s\r\n", len(p), p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	n, err := w.writer.Write(p)
+	w.bodyBytesWritten += n
+	return n, err
+}
+
+// finish completes whatever the handler left unwritten so the connection
+// always carries a well-formed response.
+func (w *ResponseWriter) finish() error {
+	if w.aborted {
+		return nil
+	}
+	if err := w.prepareBody(); err != nil {
+		return err
+	}
+
+	if !w.committed {
+		if bodyAllowed(w.statusCode) {
+			w.headers.Set("Content-Length", strconv.Itoa(len(w.buf)))
+		}
+		return w.commit()
+	}
+
+	if w.chunked && !w.trailersDone {
+		return w.WriteTrailers(NewHeaders())
+	}
+
+	return nil
+}
+
+// reset discards an uncommitted response and restores header, the fields
+// set before the handler ran.
+func (w *ResponseWriter) reset(header *Headers) bool {
+	if w.committed {
+		return false
+	}
+
+	w.writerState = writerStateStatusLine
+	w.statusCode = 0
+	w.headers = nil
+	w.header = header
+	w.header.Del("Content-Length")
+	w.header.Del("Transfer-Encoding")
+	w.buf = nil
+	return true
+}
+
+// abort leaves a response that was already sent unterminated so the client
+// can tell it was cut short, and closes the connection after it.
+func (w *ResponseWriter) abort() {
+	w.aborted = true
+	w.keepAlive = false
+}
+
+func (w *ResponseWriter) writeFields(h *Headers) error {
+	var writeErr error
+	h.Range(func(fieldName, fieldValue string) bool {
+		if _, writeErr = fmt.Fprintf(w.writer, "%s: %s\r\n", fieldName, fieldValue); writeErr != nil {
+			return false
+		}
+		return true
+	})
+
+	return writeErr
+}
+
+func (w *ResponseWriter) canReuseConnection() bool {
+	if !w.keepAlive || !w.committed {
+		return false
+	}
+
+	if !bodyAllowed(w.statusCode) {
+		return true
+	}
+
+	if w.chunked {
+		return w.trailersDone
+	}
+
+	return w.bodyBytesWritten == w.contentLength
+}
+
+func bodyAllowed(statusCode StatusCode) bool {
+	return statusCode >= 200 && statusCode != StatusNoContent && statusCode != StatusNotModified
+}
+
+func writeStatusResponse(w *ResponseWriter, statusCode StatusCode) {
+	body := fmt.Sprintf("%d %s\n", statusCode, reasonPhrase(statusCode))
+	w.WriteStatusLine(statusCode)
+	w.WriteHeaders(GetDefaultResponseHeaders("text/plain", len(body)))
+	w.WriteBody([]byte(body))
 }
@@ -0,0 +1,203 @@
+package http_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/debobrad579/httpfromtcp/internal/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteInformational(t *testing.T) {
+	// Test: Several interim responses precede the final response
+	var buf bytes.Buffer
+	w := http.NewResponseWriter(&buf)
+	hints := http.NewHeaders()
+	hints.Add("Link", "</style.css>; rel=preload; as=style")
+	hints.Add("Link", "</script.js>; rel=preload; as=script")
+	require.NoError(t, w.WriteInformational(http.StatusProcessing, nil))
+	require.NoError(t, w.WriteInformational(http.StatusEarlyHints, hints))
+	require.NoError(t, w.WriteStatusLine(http.StatusNoContent))
+	require.NoError(t, w.WriteHeaders(http.NewHeaders()))
+	assert.Equal(t, "HTTP/1.1 102 Processing\r\n\r\n"+
+		"HTTP/1.1 103 Early Hints\r\n"+
+		"Link: </style.css>; rel=preload; as=style\r\n"+
+		"Link: </script.js>; rel=preload; as=script\r\n\r\n"+
+		"HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n", buf.String())
+
+	// Test: Interim responses are rejected after the final status line
+	require.Error(t, w.WriteInformational(http.StatusEarlyHints, nil))
+
+	// Test: Only 1xx codes other than 101 are interim responses
+	w = http.NewResponseWriter(&buf)
+	require.Error(t, w.WriteInformational(http.StatusOK, nil))
+	require.Error(t, w.WriteInformational(http.StatusSwitchingProtocols, nil))
+	require.Error(t, w.WriteStatusLine(http.StatusEarlyHints))
+}
+
+func TestWriteUnbuffered(t *testing.T) {
+	// Test: A writer created outside the server sends everything immediately
+	var buf bytes.Buffer
+	w := http.NewResponseWriter(&buf)
+	require.NoError(t, w.WriteStatusLine(http.StatusOK))
+	require.NoError(t, w.WriteHeaders(http.NewHeaders()))
+	assert.Equal(t, "HTTP/1.1 200 OK\r\nConnection: close\r\n\r\n", buf.String())
+	_, err := io.WriteString(w, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 200 OK\r\nConnection: close\r\n\r\nhello", buf.String())
+
+	// Test: Writing without a status line still reaches the writer
+	buf.Reset()
+	w = http.NewResponseWriter(&buf)
+	_, err = io.WriteString(w, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 200 OK\r\nConnection: close\r\n\r\nhello", buf.String())
+}
+
+func TestServeInformational(t *testing.T) {
+	listener := newPipeListener()
+	server, err := http.Serve(listener, func(w *http.ResponseWriter, req *http.Request) {
+		hints := http.NewHeaders()
+		hints.Set("Link", "</style.css>; rel=preload")
+		w.WriteInformational(http.StatusEarlyHints, hints)
+		if req.URL.Path == "/upload" {
+			w.WriteInformational(http.StatusContinue, nil)
+			req.BodyBytes()
+		}
+		okHandler(w, req)
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Test: Early hints reach HTTP/1.1 clients
+	conn := listener.Dial()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	go conn.Write([]byte("GET /page HTTP/1.1\r\n\r\n"))
+	statusLine, headers, _ := readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 103 Early Hints", statusLine)
+	assert.Equal(t, "</style.css>; rel=preload", headers["link"])
+	statusLine, _, body := readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "/page", body)
+
+	// Test: A handler's own 100 Continue replaces the automatic one
+	go conn.Write([]byte("POST /upload HTTP/1.1\r\nContent-Length: 5\r\nExpect: 100-continue\r\n\r\n"))
+	statusLine, _, _ = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 103 Early Hints", statusLine)
+	statusLine, _, _ = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 100 Continue", statusLine)
+	go conn.Write([]byte("hello"))
+	statusLine, _, body = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "/upload", body)
+
+	// Test: HTTP/1.0 clients only see the final response
+	conn = listener.Dial()
+	defer conn.Close()
+	reader = bufio.NewReader(conn)
+	go conn.Write([]byte("GET /old HTTP/1.0\r\n\r\n"))
+	statusLine, _, body = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "/old", body)
+}
+
+func TestServeBufferedResponses(t *testing.T) {
+	listener := newPipeListener()
+	server, err := http.Serve(listener, http.Recover(func(w *http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/status":
+			w.WriteStatusLine(http.StatusBadRequest)
+		case "/small":
+			headers := http.NewHeaders()
+			headers.Set("Content-Type", "text/plain")
+			w.WriteStatusLine(http.StatusCreated)
+			w.WriteHeaders(headers)
+			io.WriteString(w, "hello ")
+			io.WriteString(w, "world")
+		case "/large":
+			io.WriteString(w, strings.Repeat("a", 3000))
+			io.WriteString(w, strings.Repeat("b", 3000))
+		case "/no-content":
+			w.WriteStatusLine(http.StatusNoContent)
+			_, err := w.Write([]byte("ignored"))
+			assert.ErrorIs(t, err, http.ErrBodyNotAllowed)
+		case "/panic":
+			w.Header().Set("Set-Cookie", "session=abc")
+			io.WriteString(w, "partial")
+			panic("boom")
+		case "/panic-late":
+			io.WriteString(w, strings.Repeat("a", 5000))
+			panic("boom")
+		}
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	conn := listener.Dial()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// Test: Handlers that write nothing send an empty 200 response
+	go conn.Write([]byte("GET /empty HTTP/1.1\r\n\r\n"))
+	statusLine, headers, body := readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "0", headers["content-length"])
+	assert.Equal(t, "keep-alive", headers["connection"])
+	assert.NotEmpty(t, headers["date"])
+	assert.Equal(t, "", body)
+
+	// Test: A status line without headers is completed
+	go conn.Write([]byte("GET /status HTTP/1.1\r\n\r\n"))
+	statusLine, headers, _ = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 400 Bad Request", statusLine)
+	assert.Equal(t, "0", headers["content-length"])
+	assert.Equal(t, "keep-alive", headers["connection"])
+
+	// Test: Small bodies get a Content-Length
+	go conn.Write([]byte("GET /small HTTP/1.1\r\n\r\n"))
+	statusLine, headers, body = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 201 Created", statusLine)
+	assert.Equal(t, "text/plain", headers["content-type"])
+	assert.Equal(t, "11", headers["content-length"])
+	assert.Equal(t, "hello world", body)
+
+	// Test: Large bodies switch to chunked encoding
+	go conn.Write([]byte("GET /large HTTP/1.1\r\n\r\n"))
+	statusLine, headers, body = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "chunked", headers["transfer-encoding"])
+	assert.Empty(t, headers["content-length"])
+	assert.Equal(t, strings.Repeat("a", 3000)+strings.Repeat("b", 3000), body)
+	assert.Equal(t, "keep-alive", headers["connection"])
+
+	// Test: Bodies are rejected for statuses that cannot carry one
+	go conn.Write([]byte("GET /no-content HTTP/1.1\r\n\r\n"))
+	statusLine, headers, _ = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 204 No Content", statusLine)
+	assert.Empty(t, headers["content-length"])
+	assert.Equal(t, "keep-alive", headers["connection"])
+
+	// Test: A buffered response is replaced when the handler panics
+	go conn.Write([]byte("GET /panic HTTP/1.1\r\n\r\n"))
+	statusLine, headers, body = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 500 Internal Server Error", statusLine)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Empty(t, headers["set-cookie"])
+	assert.Equal(t, "500 Internal Server Error\n", body)
+
+	// Test: A panic after the response started leaves it unterminated and closes the connection
+	conn = listener.Dial()
+	defer conn.Close()
+	go conn.Write([]byte("GET /panic-late HTTP/1.1\r\n\r\nGET /next HTTP/1.1\r\n\r\n"))
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "HTTP/1.1 200 OK\r\n"))
+	assert.Contains(t, string(data), "Transfer-Encoding: chunked\r\n")
+	assert.False(t, strings.HasSuffix(string(data), "0\r\n\r\n"))
+	assert.Equal(t, 1, strings.Count(string(data), "HTTP/1.1 "))
+}
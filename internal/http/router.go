@@ -0,0 +1,245 @@
+package http
+
+import (
+	"slices"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	segmentLiteral segmentKind = iota
+	segmentParam
+	segmentWildcard
+)
+
+type segment struct {
+	kind  segmentKind
+	value string
+}
+
+type route struct {
+	method      string
+	segments    []segment
+	handler     Handler
+	middlewares []Middleware
+	group       *RouteGroup
+}
+
+type Router struct {
+	routes      []*route
+	middlewares []Middleware
+	root        *RouteGroup
+}
+
+type RouteGroup struct {
+	router      *Router
+	parent      *RouteGroup
+	prefix      string
+	middlewares []Middleware
+}
+
+func NewRouter() *Router {
+	rt := &Router{}
+	rt.root = &RouteGroup{router: rt}
+	return rt
+}
+
+func (rt *Router) Use(middlewares ...Middleware) {
+	rt.middlewares = append(rt.middlewares, middlewares...)
+}
+
+func (rt *Router) Group(prefix string, middlewares ...Middleware) *RouteGroup {
+	return rt.root.Group(prefix, middlewares...)
+}
+
+func (rt *Router) Handle(method, pattern string, handler Handler, middlewares ...Middleware) {
+	rt.root.Handle(method, pattern, handler, middlewares...)
+}
+
+func (g *RouteGroup) Use(middlewares ...Middleware) {
+	g.middlewares = append(g.middlewares, middlewares...)
+}
+
+func (g *RouteGroup) Group(prefix string, middlewares ...Middleware) *RouteGroup {
+	return &RouteGroup{
+		router:      g.router,
+		parent:      g,
+		prefix:      g.prefix + prefix,
+		middlewares: middlewares,
+	}
+}
+
+// An empty method matches every method. Patterns may contain {name} segments
+// and a final {name...} segment that captures the rest of the path.
+func (g *RouteGroup) Handle(method, pattern string, handler Handler, middlewares ...Middleware) {
+	pattern = g.prefix + pattern
+
+	segments := []segment{}
+	parts := splitPath(pattern)
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			segments = append(segments, segment{kind: segmentLiteral, value: part})
+			continue
+		}
+
+		name := part[1 : len(part)-1]
+		if wildcard, ok := strings.CutSuffix(name, "..."); ok {
+			if i != len(parts)-1 {
+				panic("wildcard must be the last segment in pattern " + pattern)
+			}
+			segments = append(segments, segment{kind: segmentWildcard, value: wildcard})
+			continue
+		}
+
+		segments = append(segments, segment{kind: segmentParam, value: name})
+	}
+
+	g.router.routes = append(g.router.routes, &route{
+		method:      method,
+		segments:    segments,
+		handler:     handler,
+		middlewares: middlewares,
+		group:       g,
+	})
+}
+
+func (rt *Router) Serve(w *ResponseWriter, req *Request) {
+	Chain(rt.middlewares...)(rt.dispatch)(w, req)
+}
+
+func (rt *Router) dispatch(w *ResponseWriter, req *Request) {
+	switch req.RequestLine.TargetForm {
+	case AsteriskForm:
+		rt.serveServerOptions(w)
+		return
+	case AuthorityForm:
+		writeStatusResponse(w, StatusNotFound)
+		return
+	}
+
+	parts := splitPath(req.URL.Path)
+
+	var best *route
+	var bestParams map[string]string
+	allowed := []string{}
+
+	for _, r := range rt.routes {
+		params, ok := r.match(parts)
+		if !ok {
+			continue
+		}
+
+		if r.method != "" && r.method != req.RequestLine.Method {
+			if !slices.Contains(allowed, r.method) {
+				allowed = append(allowed, r.method)
+			}
+			continue
+		}
+
+		if best == nil || r.moreSpecificThan(best) {
+			best = r
+			bestParams = params
+		}
+	}
+
+	if best == nil {
+		if len(allowed) > 0 {
+			slices.Sort(allowed)
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeStatusResponse(w, StatusMethodNotAllowed)
+			return
+		}
+
+		writeStatusResponse(w, StatusNotFound)
+		return
+	}
+
+	req.pathParams = bestParams
+	best.chainedHandler()(w, req)
+}
+
+func (r *route) chainedHandler() Handler {
+	handler := Chain(r.middlewares...)(r.handler)
+	for g := r.group; g != nil; g = g.parent {
+		handler = Chain(g.middlewares...)(handler)
+	}
+
+	return handler
+}
+
+func (rt *Router) serveServerOptions(w *ResponseWriter) {
+	allowed := []string{"OPTIONS"}
+	for _, r := range rt.routes {
+		if r.method != "" && !slices.Contains(allowed, r.method) {
+			allowed = append(allowed, r.method)
+		}
+	}
+	slices.Sort(allowed)
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteStatusLine(StatusOK)
+	w.WriteHeaders(GetDefaultResponseHeaders("text/plain", 0))
+}
+
+func (r *route) match(parts []string) (map[string]string, bool) {
+	params := map[string]string{}
+
+	for i, seg := range r.segments {
+		if seg.kind == segmentWildcard {
+			params[seg.value] = strings.Join(parts[i:], "/")
+			return params, true
+		}
+
+		if i >= len(parts) {
+			return nil, false
+		}
+
+		switch seg.kind {
+		case segmentLiteral:
+			if seg.value != parts[i] {
+				return nil, false
+			}
+		case segmentParam:
+			if parts[i] == "" {
+				return nil, false
+			}
+			params[seg.value] = parts[i]
+		}
+	}
+
+	if len(parts) != len(r.segments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+func (r *route) moreSpecificThan(other *route) bool {
+	for i := 0; i < len(r.segments) && i < len(other.segments); i++ {
+		if r.segments[i].kind != other.segments[i].kind {
+			return r.segments[i].kind < other.segments[i].kind
+		}
+	}
+
+	if len(r.segments) != len(other.segments) {
+		return len(r.segments) > len(other.segments)
+	}
+
+	return r.method != "" && other.method == ""
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+func (r *Request) PathValue(name string) string {
+	return r.pathParams[name]
+}
+
+func (r *Request) SetPathValue(name, value string) {
+	if r.pathParams == nil {
+		r.pathParams = map[string]string{}
+	}
+	r.pathParams[name] = value
+}
@@ -0,0 +1,100 @@
+package http_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/debobrad579/httpfromtcp/internal/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveRequest(t *testing.T, handler http.Handler, method, target string) string {
+	t.Helper()
+
+	reader := &chunkReader{
+		data:            method + " " + target + " HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 1024,
+	}
+	req, err := http.RequestFromReader(reader)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	handler(http.NewResponseWriter(&buf), req)
+	return buf.String()
+}
+
+func textHandler(text func(req *http.Request) string) http.Handler {
+	return func(w *http.ResponseWriter, req *http.Request) {
+		body := text(req)
+		w.WriteStatusLine(http.StatusOK)
+		w.WriteHeaders(http.GetDefaultResponseHeaders("text/plain", len(body)))
+		w.WriteBody([]byte(body))
+	}
+}
+
+func TestRouter(t *testing.T) {
+	router := http.NewRouter()
+	router.Handle("GET", "/", textHandler(func(req *http.Request) string { return "index" }))
+	router.Handle("GET", "/users/{id}", textHandler(func(req *http.Request) string { return "user " + req.PathValue("id") }))
+	router.Handle("GET", "/users/me", textHandler(func(req *http.Request) string { return "me" }))
+	router.Handle("DELETE", "/users/{id}", textHandler(func(req *http.Request) string { return "deleted " + req.PathValue("id") }))
+	router.Handle("GET", "/static/{path...}", textHandler(func(req *http.Request) string { return "file " + req.PathValue("path") }))
+	router.Handle("", "/any", textHandler(func(req *http.Request) string { return "any " + req.RequestLine.Method }))
+
+	// Test: Literal root path
+	res := serveRequest(t, router.Serve, "GET", "/")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 200 OK\r\n"))
+	assert.True(t, strings.HasSuffix(res, "\r\n\r\nindex"))
+
+	// Test: Named path parameter
+	res = serveRequest(t, router.Serve, "GET", "/users/42")
+	assert.True(t, strings.HasSuffix(res, "user 42"))
+
+	// Test: Literal segments win over parameters
+	res = serveRequest(t, router.Serve, "GET", "/users/me")
+	assert.True(t, strings.HasSuffix(res, "\r\n\r\nme"))
+
+	// Test: Method selects between routes with the same pattern
+	res = serveRequest(t, router.Serve, "DELETE", "/users/42")
+	assert.True(t, strings.HasSuffix(res, "deleted 42"))
+
+	// Test: Wildcard captures the rest of the path
+	res = serveRequest(t, router.Serve, "GET", "/static/css/styles.css")
+	assert.True(t, strings.HasSuffix(res, "file css/styles.css"))
+
+	// Test: Empty method matches any method
+	res = serveRequest(t, router.Serve, "PATCH", "/any")
+	assert.True(t, strings.HasSuffix(res, "any PATCH"))
+
+	// Test: Query string is ignored when matching
+	res = serveRequest(t, router.Serve, "GET", "/users/7?verbose=1")
+	assert.True(t, strings.HasSuffix(res, "user 7"))
+
+	// Test: Known path with the wrong method
+	res = serveRequest(t, router.Serve, "POST", "/users/42")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 405 Method Not Allowed\r\n"))
+	assert.Contains(t, res, "Allow: DELETE, GET\r\n")
+
+	// Test: Unknown path
+	res = serveRequest(t, router.Serve, "GET", "/missing")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 404 Not Found\r\n"))
+
+	// Test: Missing parameter value does not match
+	res = serveRequest(t, router.Serve, "GET", "/users/")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 404 Not Found\r\n"))
+
+	// Test: Server-wide OPTIONS lists every registered method
+	res = serveRequest(t, router.Serve, "OPTIONS", "*")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 200 OK\r\n"))
+	assert.Contains(t, res, "Allow: DELETE, GET, OPTIONS\r\n")
+
+	// Test: Absolute-form targets are routed by path
+	res = serveRequest(t, router.Serve, "GET", "http://localhost:42069/users/9")
+	assert.True(t, strings.HasSuffix(res, "user 9"))
+
+	// Test: Authority-form targets match no route
+	res = serveRequest(t, router.Serve, "CONNECT", "localhost:443")
+	assert.True(t, strings.HasPrefix(res, "HTTP/1.1 404 Not Found\r\n"))
+}
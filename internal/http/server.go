@@ -1,31 +1,144 @@
 package http
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
+	"os"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Handler func(w *ResponseWriter, req *Request)
 
+const shutdownPollInterval = 100 * time.Millisecond
+
+type connState int
+
+const (
+	connStateActive connState = iota
+	connStateIdle
+)
+
 type Server struct {
-	Port     uint16
-	listener net.Listener
-	handler  Handler
-	isClosed atomic.Bool
+	Port       uint16
+	listener   net.Listener
+	handler    Handler
+	config     ServerConfig
+	isClosed   atomic.Bool
+	mu         sync.Mutex
+	conns      map[net.Conn]connState
+	onShutdown []func()
+	baseCtx    context.Context
+	cancelBase context.CancelFunc
+}
+
+func NewServer(handler Handler, config ServerConfig) *Server {
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	return &Server{
+		handler:    handler,
+		config:     config.withDefaults(),
+		baseCtx:    baseCtx,
+		cancelBase: cancelBase,
+	}
 }
 
 func (s *Server) Close() error {
+	err := s.closeListener()
+	s.cancelBase()
+	s.closeConns(false)
+	return err
+}
+
+// Shutdown stops accepting connections and waits for active ones to finish.
+// Request contexts are only cancelled once ctx expires, so long-running
+// handlers should use RegisterOnShutdown to learn that a shutdown started.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.closeListener()
+
+	s.mu.Lock()
+	for _, f := range s.onShutdown {
+		go f()
+	}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.closeConns(true) == 0 {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			s.cancelBase()
+			s.closeConns(false)
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onShutdown = append(s.onShutdown, f)
+}
+
+func (s *Server) closeListener() error {
 	s.isClosed.Store(true)
+	if s.listener == nil {
+		return nil
+	}
+
 	return s.listener.Close()
 }
 
+func (s *Server) closeConns(idleOnly bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn, state := range s.conns {
+		if idleOnly && state != connStateIdle {
+			continue
+		}
+
+		conn.Close()
+	}
+
+	return len(s.conns)
+}
+
+func (s *Server) setConnState(conn net.Conn, state connState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]connState)
+	}
+	s.conns[conn] = state
+}
+
+func (s *Server) removeConn(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conns, conn)
+}
+
 func (s *Server) listen() {
 	for {
 		conn, err := s.listener.Accept()
 		if err != nil {
-			if s.isClosed.Load() {
+			if s.isClosed.Load() || errors.Is(err, net.ErrClosed) {
 				return
 			}
 
@@ -33,11 +146,13 @@ func (s *Server) listen() {
 			continue
 		}
 
+		s.setConnState(conn, connStateIdle)
 		go s.handle(conn)
 	}
 }
 
 func (s *Server) handle(conn net.Conn) {
+	defer s.removeConn(conn)
 	defer conn.Close()
 	defer func() {
 		if r := recover(); r != nil {
@@ -45,29 +160,262 @@ func (s *Server) handle(conn net.Conn) {
 		}
 	}()
 
-	req, err := RequestFromReader(conn)
-	if err != nil {
+	var tlsState *tls.ConnectionState
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := conn.SetDeadline(deadline(s.config.ReadHeaderTimeout)); err != nil {
+			log.Println(err)
+			return
+		}
+
+		if err := tlsConn.Handshake(); err != nil {
+			if !isConnClosedError(err) {
+				log.Println(err)
+			}
+			return
+		}
+
+		state := tlsConn.ConnectionState()
+		tlsState = &state
+	}
+
+	connCtx, cancelConn := context.WithCancel(s.baseCtx)
+	defer cancelConn()
+
+	connReader := newConnReader(conn, cancelConn)
+	reqReader := NewRequestReader(connReader)
+	reqReader.MaxRequestLineBytes = s.config.MaxRequestLineBytes
+	reqReader.MaxHeaderBytes = s.config.MaxHeaderBytes
+	reqReader.MaxHeaderFields = s.config.MaxHeaderFields
+	reqReader.MaxBodyBytes = s.config.MaxBodyBytes
+
+	for served := 1; !s.isClosed.Load(); served++ {
+		s.setConnState(conn, connStateIdle)
+		if err := conn.SetReadDeadline(deadline(s.config.IdleTimeout)); err != nil {
+			log.Println(err)
+			return
+		}
+
+		if err := reqReader.peek(); err != nil {
+			if !isConnClosedError(err) {
+				log.Println(err)
+			}
+			return
+		}
+
+		s.setConnState(conn, connStateActive)
+		readStart := time.Now()
+		if err := conn.SetReadDeadline(deadlineFrom(readStart, s.config.ReadHeaderTimeout)); err != nil {
+			log.Println(err)
+			return
+		}
+
+		req, err := reqReader.ReadRequest()
+		if err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				s.writeParseError(conn, parseErr)
+			} else if !isConnClosedError(err) {
+				log.Println(err)
+			}
+			return
+		}
+
+		if err := conn.SetReadDeadline(deadlineFrom(readStart, s.config.ReadTimeout)); err != nil {
+			log.Println(err)
+			return
+		}
+
+		if err := conn.SetWriteDeadline(deadline(s.config.WriteTimeout)); err != nil {
+			log.Println(err)
+			return
+		}
+
+		reqCtx, cancelReq := context.WithCancel(connCtx)
+		req.ctx = reqCtx
+		req.TLS = tlsState
+
+		resWriter := NewResponseWriter(conn)
+		resWriter.buffered = true
+		resWriter.keepAlive = wantsKeepAlive(req) && (s.config.MaxRequestsPerConn < 0 || served < s.config.MaxRequestsPerConn)
+		resWriter.serverClosed = &s.isClosed
+		resWriter.http10 = req.RequestLine.HttpVersion == "1.0"
+		resWriter.reqBody = reqReader.body
+
+		if expect := req.Headers.Get("Expect"); expect != "" && !resWriter.http10 {
+			if status := s.checkExpect(req, expect); status != StatusContinue {
+				resWriter.keepAlive = false
+				writeStatusResponse(resWriter, status)
+				cancelReq()
+				return
+			}
+			reqReader.body.setOnFirstRead(resWriter.writeContinue)
+		}
+
+		reqReader.body.setOnEOF(func() {
+			if len(reqReader.buffered()) == 0 {
+				connReader.startBackgroundRead()
+			}
+		})
+		s.handler(resWriter, req)
+		if err := resWriter.finish(); err != nil && !isConnClosedError(err) {
+			log.Println(err)
+		}
+
+		cancelReq()
+		reqReader.body.setOnEOF(nil)
+		connReader.abortPendingRead()
+
+		if !resWriter.canReuseConnection() || reqReader.body.awaitingFirstRead() {
+			return
+		}
+
+		// The response is already sent, so a body that fails to decode here
+		// just ends the connection instead of producing another response.
+		if err := reqReader.discardBody(); err != nil {
+			if !isConnClosedError(err) {
+				log.Println(err)
+			}
+			return
+		}
+	}
+}
+
+func (s *Server) writeParseError(conn net.Conn, err *ParseError) {
+	if err := conn.SetWriteDeadline(deadline(s.config.WriteTimeout)); err != nil {
 		log.Println(err)
 		return
 	}
 
 	resWriter := NewResponseWriter(conn)
-	s.handler(resWriter, req)
+	resWriter.buffered = true
+	resWriter.defaultStatus = err.StatusCode
+	s.config.ErrorHandler(resWriter, err)
+	if err := resWriter.finish(); err != nil && !isConnClosedError(err) {
+		log.Println(err)
+	}
 }
 
-func ListenAndServe(port uint16, handler Handler) (*Server, error) {
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+func (s *Server) checkExpect(req *Request, expect string) StatusCode {
+	if !strings.EqualFold(expect, "100-continue") {
+		return StatusExpectationFailed
+	}
+	if s.config.CheckContinue == nil {
+		return StatusContinue
+	}
+
+	return s.config.CheckContinue(req)
+}
+
+func wantsKeepAlive(req *Request) bool {
+	if req.RequestLine.HttpVersion == "1.0" {
+		return hasToken(req.Headers.Get("Connection"), "keep-alive")
+	}
+
+	return !hasToken(req.Headers.Get("Connection"), "close")
+}
+
+func hasToken(fieldValue, token string) bool {
+	for v := range strings.SplitSeq(fieldValue, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isConnClosedError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	if s.isClosed.Load() {
+		return errors.New("server closed")
+	}
+
+	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok {
+		s.Port = uint16(tcpAddr.Port)
+	}
+	s.listener = listener
+
+	go s.listen()
+
+	return nil
+}
+
+func (s *Server) ListenAndServe(port uint16) error {
+	return s.ListenAndServeAddr(fmt.Sprintf(":%d", port))
+}
+
+func (s *Server) ListenAndServeAddr(addr string) error {
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+func (s *Server) ListenAndServeUnix(path string) error {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Addr()
+}
+
+func Serve(listener net.Listener, handler Handler) (*Server, error) {
+	server := NewServer(handler, ServerConfig{})
+	if err := server.Serve(listener); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+func ListenAndServe(port uint16, handler Handler) (*Server, error) {
+	server := NewServer(handler, ServerConfig{})
+	if err := server.ListenAndServe(port); err != nil {
 		return nil, err
 	}
 
-	server := &Server{
-		Port:     port,
-		listener: listener,
-		handler:  handler,
+	return server, nil
+}
+
+func ListenAndServeAddr(addr string, handler Handler) (*Server, error) {
+	server := NewServer(handler, ServerConfig{})
+	if err := server.ListenAndServeAddr(addr); err != nil {
+		return nil, err
 	}
 
-	go server.listen()
+	return server, nil
+}
+
+func ListenAndServeUnix(path string, handler Handler) (*Server, error) {
+	server := NewServer(handler, ServerConfig{})
+	if err := server.ListenAndServeUnix(path); err != nil {
+		return nil, err
+	}
 
 	return server, nil
 }
@@ -0,0 +1,626 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/debobrad579/httpfromtcp/internal/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+type pipeListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+func (l *pipeListener) Dial() net.Conn {
+	serverConn, clientConn := net.Pipe()
+	l.conns <- serverConn
+	return clientConn
+}
+
+func okHandler(w *http.ResponseWriter, req *http.Request) {
+	body := req.RequestLine.RequestTarget
+	w.WriteStatusLine(http.StatusOK)
+	w.WriteHeaders(http.GetDefaultResponseHeaders("text/plain", len(body)))
+	w.WriteBody([]byte(body))
+}
+
+func readResponse(t *testing.T, reader *bufio.Reader) (string, map[string]string, string) {
+	t.Helper()
+
+	statusLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+
+	headers := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, _ := strings.Cut(line, ":")
+		headers[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+
+	var body []byte
+	if headers["transfer-encoding"] == "chunked" {
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			size, err := strconv.ParseInt(strings.TrimRight(line, "\r\n"), 16, 64)
+			require.NoError(t, err)
+			chunk := make([]byte, size+2)
+			_, err = io.ReadFull(reader, chunk)
+			require.NoError(t, err)
+			if size == 0 {
+				break
+			}
+			body = append(body, chunk[:size]...)
+		}
+	} else if contentLength, err := strconv.Atoi(headers["content-length"]); err == nil {
+		body = make([]byte, contentLength)
+		_, err = io.ReadFull(reader, body)
+		require.NoError(t, err)
+	}
+
+	return strings.TrimRight(statusLine, "\r\n"), headers, string(body)
+}
+
+func TestServeKeepAlive(t *testing.T) {
+	listener := newPipeListener()
+	server, err := http.Serve(listener, okHandler)
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Test: Several requests are answered on the same connection
+	conn := listener.Dial()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	go conn.Write([]byte("GET /first HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	statusLine, headers, body := readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "keep-alive", headers["connection"])
+	assert.Equal(t, "/first", body)
+
+	go conn.Write([]byte("GET /second HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n"))
+	statusLine, headers, body = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Equal(t, "/second", body)
+
+	_, err = reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// Test: Pipelined requests are answered in order
+	conn = listener.Dial()
+	defer conn.Close()
+	reader = bufio.NewReader(conn)
+
+	go conn.Write([]byte("GET /a HTTP/1.1\r\nHost: localhost\r\n\r\n" +
+		"GET /b HTTP/1.1\r\nHost: localhost\r\n\r\n" +
+		"GET /c HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n"))
+	for _, target := range []string{"/a", "/b", "/c"} {
+		_, _, body = readResponse(t, reader)
+		assert.Equal(t, target, body)
+	}
+}
+
+func TestServeConnectionLimits(t *testing.T) {
+	// Test: Idle connections are closed after IdleTimeout
+	listener := newPipeListener()
+	server := http.NewServer(okHandler, http.ServerConfig{IdleTimeout: 50 * time.Millisecond})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	conn := listener.Dial()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	go conn.Write([]byte("GET /first HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	_, headers, _ := readResponse(t, reader)
+	assert.Equal(t, "keep-alive", headers["connection"])
+	start := time.Now()
+	_, err := reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Test: The last request allowed by MaxRequestsPerConn closes the connection
+	listener = newPipeListener()
+	server = http.NewServer(okHandler, http.ServerConfig{MaxRequestsPerConn: 2})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	conn = listener.Dial()
+	defer conn.Close()
+	reader = bufio.NewReader(conn)
+	go conn.Write([]byte("GET /a HTTP/1.1\r\nHost: localhost\r\n\r\nGET /b HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	_, headers, body := readResponse(t, reader)
+	assert.Equal(t, "keep-alive", headers["connection"])
+	assert.Equal(t, "/a", body)
+	_, headers, body = readResponse(t, reader)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Equal(t, "/b", body)
+	_, err = reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// Test: Negative MaxRequestsPerConn removes the cutoff
+	listener = newPipeListener()
+	server = http.NewServer(okHandler, http.ServerConfig{MaxRequestsPerConn: -1})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	conn = listener.Dial()
+	defer conn.Close()
+	reader = bufio.NewReader(conn)
+	for i := range 150 {
+		go conn.Write([]byte("GET /" + strconv.Itoa(i) + " HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+		_, headers, _ = readResponse(t, reader)
+		require.Equal(t, "keep-alive", headers["connection"])
+	}
+
+	// Test: A client Connection: close ends the connection after one response
+	go conn.Write([]byte("GET /last HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n"))
+	_, headers, body = readResponse(t, reader)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Equal(t, "/last", body)
+	_, err = reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+// trickle writes data one byte at a time until the connection fails.
+func trickle(conn net.Conn, data string) {
+	for i := 0; ; i = (i + 1) % len(data) {
+		if _, err := conn.Write([]byte{data[i]}); err != nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServeTimeouts(t *testing.T) {
+	// Test: A client trickling header fields is cut off after ReadHeaderTimeout
+	listener := newPipeListener()
+	server := http.NewServer(okHandler, http.ServerConfig{ReadHeaderTimeout: 100 * time.Millisecond})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	conn := listener.Dial()
+	defer conn.Close()
+	start := time.Now()
+	go func() {
+		if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n")); err == nil {
+			trickle(conn, "X-Slow: a\r\n")
+		}
+	}()
+	_, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Test: A client trickling the body is cut off after ReadTimeout
+	listener = newPipeListener()
+	bodyErr := make(chan error, 1)
+	server = http.NewServer(func(w *http.ResponseWriter, req *http.Request) {
+		_, err := req.BodyBytes()
+		bodyErr <- err
+	}, http.ServerConfig{ReadTimeout: 100 * time.Millisecond})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	conn = listener.Dial()
+	defer conn.Close()
+	start = time.Now()
+	go func() {
+		if _, err := conn.Write([]byte("POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 1000\r\n\r\n")); err == nil {
+			trickle(conn, "a")
+		}
+	}()
+	_, err = io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Error(t, <-bodyErr)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Test: A client that stops reading is cut off after WriteTimeout
+	listener = newPipeListener()
+	writeErr := make(chan error, 1)
+	server = http.NewServer(func(w *http.ResponseWriter, req *http.Request) {
+		_, err := w.Write(make([]byte, 1024*1024))
+		writeErr <- err
+	}, http.ServerConfig{WriteTimeout: 100 * time.Millisecond})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	conn = listener.Dial()
+	defer conn.Close()
+	start = time.Now()
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+	select {
+	case err := <-writeErr:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("handler write did not time out")
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestServeUnix(t *testing.T) {
+	// Test: Requests are served over a unix domain socket
+	path := filepath.Join(t.TempDir(), "server.sock")
+	server, err := http.ListenAndServeUnix(path, okHandler)
+	require.NoError(t, err)
+	defer server.Close()
+	assert.Equal(t, "unix", server.Addr().Network())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /unix HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+	statusLine, _, body := readResponse(t, bufio.NewReader(conn))
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "/unix", body)
+}
+
+func TestShutdown(t *testing.T) {
+	listener := newPipeListener()
+	started := make(chan struct{})
+	server, err := http.Serve(listener, func(w *http.ResponseWriter, req *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		okHandler(w, req)
+	})
+	require.NoError(t, err)
+
+	idleConn := listener.Dial()
+	defer idleConn.Close()
+
+	conn := listener.Dial()
+	defer conn.Close()
+	go conn.Write([]byte("GET /slow HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	<-started
+
+	// Test: In-flight requests finish and idle connections are closed
+	responses := make(chan string, 1)
+	go func() {
+		_, headers, body := readResponse(t, bufio.NewReader(conn))
+		responses <- headers["connection"] + " " + body
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	assert.Equal(t, "close /slow", <-responses)
+
+	_, err = idleConn.Read(make([]byte, 1))
+	assert.Error(t, err)
+
+	// Test: Shutdown gives up when the context expires
+	listener = newPipeListener()
+	started = make(chan struct{})
+	server, err = http.Serve(listener, func(w *http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-req.Context().Done()
+	})
+	require.NoError(t, err)
+
+	conn = listener.Dial()
+	defer conn.Close()
+	go conn.Write([]byte("GET /stuck HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	<-started
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, server.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestShutdownStopsAccepting(t *testing.T) {
+	listener := newPipeListener()
+	server, err := http.Serve(listener, okHandler)
+	require.NoError(t, err)
+
+	// Test: Shutdown without connections returns immediately
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, server.Shutdown(ctx))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	// Test: The listener is closed so no new connections are accepted
+	_, err = listener.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
+
+func TestServeRequestContext(t *testing.T) {
+	listener := newPipeListener()
+	cancelled := make(chan error, 1)
+	shuttingDown := make(chan struct{})
+	polling := make(chan struct{})
+	waiting := make(chan struct{})
+	server, err := http.Serve(listener, func(w *http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/poll" {
+			close(polling)
+			<-shuttingDown
+			okHandler(w, req)
+			return
+		}
+
+		close(waiting)
+		<-req.Context().Done()
+		cancelled <- req.Context().Err()
+	})
+	require.NoError(t, err)
+	server.RegisterOnShutdown(func() { close(shuttingDown) })
+
+	// Test: The request context is cancelled when the client disconnects
+	conn := listener.Dial()
+	_, err = conn.Write([]byte("GET /wait HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+	<-waiting
+	conn.Close()
+	select {
+	case err := <-cancelled:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("request context was not cancelled")
+	}
+
+	// Test: Shutdown hooks let long-polling handlers finish gracefully
+	conn = listener.Dial()
+	defer conn.Close()
+	go conn.Write([]byte("GET /poll HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	responses := make(chan string, 1)
+	go func() {
+		_, headers, body := readResponse(t, bufio.NewReader(conn))
+		responses <- headers["connection"] + " " + body
+	}()
+	<-polling
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	assert.Equal(t, "close /poll", <-responses)
+}
+
+func TestServeHTTP10(t *testing.T) {
+	listener := newPipeListener()
+	server, err := http.Serve(listener, func(w *http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/chunked" {
+			okHandler(w, req)
+			return
+		}
+
+		headers := http.NewHeaders()
+		headers.Set("Content-Type", "text/plain")
+		headers.Set("Transfer-Encoding", "chunked")
+		w.WriteStatusLine(http.StatusOK)
+		w.WriteHeaders(headers)
+		w.WriteChunkedBody([]byte("hello "))
+		w.WriteChunkedBody([]byte("world"))
+		w.WriteTrailers(http.NewHeaders())
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Test: HTTP/1.0 connections close by default
+	conn := listener.Dial()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	go conn.Write([]byte("GET /plain HTTP/1.0\r\n\r\n"))
+	_, headers, body := readResponse(t, reader)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Equal(t, "/plain", body)
+	_, err = reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// Test: HTTP/1.0 keep-alive only when requested
+	conn = listener.Dial()
+	defer conn.Close()
+	reader = bufio.NewReader(conn)
+	go conn.Write([]byte("GET /first HTTP/1.0\r\nConnection: keep-alive\r\n\r\n"))
+	_, headers, body = readResponse(t, reader)
+	assert.Equal(t, "keep-alive", headers["connection"])
+	assert.Equal(t, "/first", body)
+	go conn.Write([]byte("GET /second HTTP/1.0\r\n\r\n"))
+	_, headers, body = readResponse(t, reader)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Equal(t, "/second", body)
+
+	// Test: Chunked responses are sent unframed to HTTP/1.0 clients
+	conn = listener.Dial()
+	defer conn.Close()
+	go conn.Write([]byte("GET /chunked HTTP/1.0\r\nConnection: keep-alive\r\n\r\n"))
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.NotContains(t, strings.ToLower(string(data)), "transfer-encoding")
+	assert.Contains(t, string(data), "Connection: close\r\n")
+	assert.True(t, strings.HasSuffix(string(data), "\r\n\r\nhello world"))
+
+	// Test: Unsupported versions get a 505 response
+	conn = listener.Dial()
+	defer conn.Close()
+	go conn.Write([]byte("GET / HTTP/2.0\r\nHost: localhost\r\n\r\n"))
+	statusLine, headers, _ := readResponse(t, bufio.NewReader(conn))
+	assert.Equal(t, "HTTP/1.1 505 HTTP Version Not Supported", statusLine)
+	assert.Equal(t, "close", headers["connection"])
+}
+
+func TestServeParseErrors(t *testing.T) {
+	listener := newPipeListener()
+	server, err := http.Serve(listener, okHandler)
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Test: Malformed requests get a 400 response before the connection closes
+	conn := listener.Dial()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	go conn.Write([]byte("GET / HTTP/1.1\r\nHost localhost\r\n\r\n"))
+	statusLine, headers, body := readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 400 Bad Request", statusLine)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Equal(t, "400 Bad Request\n", body)
+	_, err = reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// Test: A bad body left unread by the handler closes the connection silently
+	conn = listener.Dial()
+	defer conn.Close()
+	reader = bufio.NewReader(conn)
+	go conn.Write([]byte("POST /ignored HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\n\r\nZZ\r\n"))
+	statusLine, _, body = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "/ignored", body)
+	_, err = reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// Test: Custom error handler
+	listener = newPipeListener()
+	server = http.NewServer(okHandler, http.ServerConfig{
+		MaxHeaderBytes: 64,
+		ErrorHandler: func(w *http.ResponseWriter, err *http.ParseError) {
+			body := "custom: " + err.Error()
+			w.WriteStatusLine(err.StatusCode)
+			w.WriteHeaders(http.GetDefaultResponseHeaders("text/plain", len(body)))
+			w.WriteBody([]byte(body))
+		},
+	})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	conn = listener.Dial()
+	defer conn.Close()
+	go conn.Write([]byte("GET / HTTP/1.1\r\nX-Padding: " + strings.Repeat("a", 100) + "\r\n\r\n"))
+	statusLine, _, body = readResponse(t, bufio.NewReader(conn))
+	assert.Equal(t, "HTTP/1.1 431 Request Header Fields Too Large", statusLine)
+	assert.Equal(t, "custom: request header too large", body)
+
+	// Test: A custom error handler that only writes a body keeps the error status
+	listener = newPipeListener()
+	server = http.NewServer(okHandler, http.ServerConfig{
+		ErrorHandler: func(w *http.ResponseWriter, err *http.ParseError) {
+			io.WriteString(w, "bad request")
+		},
+	})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	conn = listener.Dial()
+	defer conn.Close()
+	go conn.Write([]byte("GET / HTTP/1.1\r\nHost localhost\r\n\r\n"))
+	statusLine, headers, body = readResponse(t, bufio.NewReader(conn))
+	assert.Equal(t, "HTTP/1.1 400 Bad Request", statusLine)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Equal(t, "bad request", body)
+}
+
+func TestServeExpectContinue(t *testing.T) {
+	listener := newPipeListener()
+	server := http.NewServer(func(w *http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/ignore" {
+			okHandler(w, req)
+			return
+		}
+
+		body, err := req.BodyBytes()
+		require.NoError(t, err)
+		w.WriteStatusLine(http.StatusOK)
+		w.WriteHeaders(http.GetDefaultResponseHeaders("text/plain", len(body)))
+		w.WriteBody(body)
+	}, http.ServerConfig{
+		MaxBodyBytes: 100,
+		CheckContinue: func(req *http.Request) http.StatusCode {
+			if req.URL.Path == "/reject" {
+				return http.StatusExpectationFailed
+			}
+			return http.StatusContinue
+		},
+	})
+	require.NoError(t, server.Serve(listener))
+	defer server.Close()
+
+	// Test: 100 Continue is sent before the handler reads the body
+	conn := listener.Dial()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	go conn.Write([]byte("POST /upload HTTP/1.1\r\nContent-Length: 5\r\nExpect: 100-continue\r\n\r\n"))
+	statusLine, _, _ := readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 100 Continue", statusLine)
+	go conn.Write([]byte("hello"))
+	statusLine, headers, body := readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "keep-alive", headers["connection"])
+	assert.Equal(t, "hello", body)
+
+	// Test: Handlers that never read the body skip 100 Continue and close the connection
+	go conn.Write([]byte("POST /ignore HTTP/1.1\r\nContent-Length: 5\r\nExpect: 100-continue\r\n\r\n"))
+	statusLine, headers, body = readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	assert.Equal(t, "close", headers["connection"])
+	assert.Equal(t, "/ignore", body)
+	_, err := reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// Test: The pre-check hook rejects the request without reading the body
+	tests := []struct {
+		data       string
+		statusLine string
+	}{
+		{"POST /reject HTTP/1.1\r\nContent-Length: 5\r\nExpect: 100-continue\r\n\r\n", "HTTP/1.1 417 Expectation Failed"},
+		{"POST /upload HTTP/1.1\r\nContent-Length: 500\r\nExpect: 100-continue\r\n\r\n", "HTTP/1.1 413 Request Entity Too Large"},
+		{"POST /upload HTTP/1.1\r\nContent-Length: 5\r\nExpect: something-else\r\n\r\n", "HTTP/1.1 417 Expectation Failed"},
+	}
+	for _, tc := range tests {
+		conn := listener.Dial()
+		reader := bufio.NewReader(conn)
+		go conn.Write([]byte(tc.data))
+		statusLine, headers, _ := readResponse(t, reader)
+		assert.Equal(t, tc.statusLine, statusLine)
+		assert.Equal(t, "close", headers["connection"])
+		conn.Close()
+	}
+}
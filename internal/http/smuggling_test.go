@@ -0,0 +1,175 @@
+package http_test
+
+import (
+	"bufio"
+	"io"
+	"testing"
+
+	"github.com/debobrad579/httpfromtcp/internal/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmugglingPayloads(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		statusCode http.StatusCode
+	}{
+		{
+			name: "CL.TE",
+			data: "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 13\r\nTransfer-Encoding: chunked\r\n\r\n" +
+				"0\r\n\r\nSMUGGLED",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "TE.CL",
+			data: "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\nContent-Length: 3\r\n\r\n" +
+				"8\r\nSMUGGLED\r\n0\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "TE.TE with an unknown coding",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\nTransfer-Encoding: identity\r\n\r\n",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "Obfuscated chunked coding",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: xchunked\r\n\r\n",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "Empty Transfer-Encoding with Content-Length",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding:\r\nContent-Length: 5\r\n\r\nhello",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Transfer-Encoding in HTTP/1.0",
+			data:       "POST / HTTP/1.0\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\n\r\n0\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Differing duplicate Content-Length fields",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5\r\nContent-Length: 6\r\n\r\nhello!",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Differing Content-Length list",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5, 6\r\n\r\nhello!",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Negative Content-Length",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: -1\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Signed Content-Length",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: +5\r\n\r\nhello",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Hexadecimal Content-Length",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 0x5\r\n\r\nhello",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Empty Content-Length",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length:\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Overflowing Content-Length",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 99999999999999999999999\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Obsolete line folding",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nX-Folded: a\r\n Transfer-Encoding: chunked\r\nContent-Length: 5\r\n\r\nhello",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Folded Transfer-Encoding",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding:\r\n\tchunked\r\n\r\n0\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Whitespace before the first field line",
+			data:       "POST / HTTP/1.1\r\n Transfer-Encoding: chunked\r\nHost: localhost\r\n\r\n0\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Tab before colon",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding\t: chunked\r\n\r\n0\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Space before colon",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding : chunked\r\nContent-Length: 5\r\n\r\nhello",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Bare LF inside a field line",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nX-Test: a\nTransfer-Encoding: chunked\r\nContent-Length: 5\r\n\r\nhello",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Negative chunk size",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\n\r\n-5\r\nhello\r\n0\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Prefixed chunk size",
+			data:       "POST / HTTP/1.1\r\nHost: localhost\r\nTransfer-Encoding: chunked\r\n\r\n0x5\r\nhello\r\n0\r\n\r\n",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		// Test: Ambiguous framing is rejected with a parse error
+		r, err := http.RequestFromReader(&chunkReader{data: tc.data, numBytesPerRead: 8})
+		if err == nil {
+			_, err = r.BodyBytes()
+		}
+		var parseErr *http.ParseError
+		require.ErrorAs(t, err, &parseErr, tc.name)
+		assert.Equal(t, tc.statusCode, parseErr.StatusCode, tc.name)
+	}
+}
+
+func TestContentLengthFraming(t *testing.T) {
+	// Test: Identical duplicate Content-Length fields are accepted
+	r, err := http.RequestFromReader(&chunkReader{
+		data:            "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5\r\nContent-Length: 5\r\n\r\nhello",
+		numBytesPerRead: 8,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", readBody(t, r))
+
+	// Test: Identical Content-Length list is accepted
+	r, err = http.RequestFromReader(&chunkReader{
+		data:            "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 5, 5\r\n\r\nhello",
+		numBytesPerRead: 8,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", readBody(t, r))
+}
+
+func TestServeSmuggledRequest(t *testing.T) {
+	listener := newPipeListener()
+	server, err := http.Serve(listener, okHandler)
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Test: A smuggled request is never served and the connection is closed
+	conn := listener.Dial()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	go conn.Write([]byte("POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 44\r\nTransfer-Encoding: chunked\r\n\r\n" +
+		"0\r\n\r\nGET /smuggled HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	statusLine, headers, _ := readResponse(t, reader)
+	assert.Equal(t, "HTTP/1.1 400 Bad Request", statusLine)
+	assert.Equal(t, "close", headers["connection"])
+	_, err = reader.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -149,3 +149,11 @@ var reasonPhrases = map[StatusCode]string{
 	StatusNotExtended:                   "Not Extended",
 	StatusNetworkAuthenticationRequired: "Network Authentication Required",
 }
+
+func reasonPhrase(statusCode StatusCode) string {
+	if reasonPhrase, ok := reasonPhrases[statusCode]; ok {
+		return reasonPhrase
+	}
+
+	return "Unknown"
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const certReloadInterval = 30 * time.Second
+
+type certificateFile struct {
+	certFile string
+	keyFile  string
+	modTime  time.Time
+	cert     *tls.Certificate
+}
+
+type CertificateStore struct {
+	mu        sync.RWMutex
+	certs     []*certificateFile
+	checkedAt time.Time
+}
+
+func NewCertificateStore() *CertificateStore {
+	return &CertificateStore{}
+}
+
+func (cs *CertificateStore) AddFile(certFile, keyFile string) error {
+	cf := &certificateFile{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := cf.load(); err != nil {
+		return err
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.certs = append(cs.certs, cf)
+	cs.checkedAt = time.Now()
+	return nil
+}
+
+func (cs *CertificateStore) Reload() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.checkedAt = time.Now()
+
+	var errs []error
+	for _, cf := range cs.certs {
+		modTime, err := cf.latestModTime()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !modTime.After(cf.modTime) {
+			continue
+		}
+
+		if err := cf.load(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (cs *CertificateStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cs.mu.RLock()
+	stale := time.Since(cs.checkedAt) > certReloadInterval
+	cs.mu.RUnlock()
+
+	if stale {
+		if err := cs.Reload(); err != nil {
+			log.Println(err)
+		}
+	}
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if len(cs.certs) == 0 {
+		return nil, errors.New("no certificates configured")
+	}
+
+	for _, cf := range cs.certs {
+		if hello.SupportsCertificate(cf.cert) == nil {
+			return cf.cert, nil
+		}
+	}
+
+	return cs.certs[0].cert, nil
+}
+
+func (s *Server) ServeTLS(listener net.Listener, certs *CertificateStore) error {
+	var config *tls.Config
+	if s.config.TLSConfig != nil {
+		config = s.config.TLSConfig.Clone()
+	} else {
+		config = &tls.Config{}
+	}
+
+	config.GetCertificate = certs.GetCertificate
+	if s.config.ClientCAs != nil {
+		config.ClientCAs = s.config.ClientCAs
+	}
+	if s.config.ClientAuth != tls.NoClientCert {
+		config.ClientAuth = s.config.ClientAuth
+	}
+	config.NextProtos = []string{"http/1.1"}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	return s.Serve(tls.NewListener(listener, config))
+}
+
+func (s *Server) ListenAndServeTLS(port uint16, certs *CertificateStore) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+
+	return s.ServeTLS(listener, certs)
+}
+
+func ListenAndServeTLS(port uint16, certFile, keyFile string, handler Handler) (*Server, error) {
+	certs := NewCertificateStore()
+	if err := certs.AddFile(certFile, keyFile); err != nil {
+		return nil, err
+	}
+
+	server := NewServer(handler, ServerConfig{})
+	if err := server.ListenAndServeTLS(port, certs); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+func (cf *certificateFile) load() error {
+	modTime, err := cf.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(cf.certFile, cf.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cf.cert = &cert
+	cf.modTime = modTime
+	return nil
+}
+
+func (cf *certificateFile) latestModTime() (time.Time, error) {
+	certInfo, err := os.Stat(cf.certFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	keyInfo, err := os.Stat(cf.keyFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if keyInfo.ModTime().After(certInfo.ModTime()) {
+		return keyInfo.ModTime(), nil
+	}
+
+	return certInfo.ModTime(), nil
+}
@@ -0,0 +1,212 @@
+package http_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/debobrad579/httpfromtcp/internal/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCert(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent = template
+		parentKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+func writeTestCert(t *testing.T, dir, name string, cert *x509.Certificate, key *ecdsa.PrivateKey) (string, string) {
+	t.Helper()
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile
+}
+
+func writeSelfSignedCert(t *testing.T, dir, name string, serial int64) (string, string) {
+	t.Helper()
+
+	cert, key := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, nil, nil)
+	return writeTestCert(t, dir, name, cert, key)
+}
+
+func getOverTLS(t *testing.T, addr, serverName string) (*x509.Certificate, string) {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: " + serverName + "\r\nConnection: close\r\n\r\n"))
+	require.NoError(t, err)
+	statusLine, _, body := readResponse(t, bufio.NewReader(conn))
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+
+	return conn.ConnectionState().PeerCertificates[0], body
+}
+
+func TestServeTLS(t *testing.T) {
+	dir := t.TempDir()
+	certs := http.NewCertificateStore()
+	certFile, keyFile := writeSelfSignedCert(t, dir, "a.example.com", 1)
+	require.NoError(t, certs.AddFile(certFile, keyFile))
+	certFile, keyFile = writeSelfSignedCert(t, dir, "b.example.com", 2)
+	require.NoError(t, certs.AddFile(certFile, keyFile))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := http.NewServer(func(w *http.ResponseWriter, req *http.Request) {
+		body := ""
+		if req.TLS != nil {
+			body = req.TLS.ServerName
+		}
+		w.WriteStatusLine(http.StatusOK)
+		w.WriteHeaders(http.GetDefaultResponseHeaders("text/plain", len(body)))
+		w.WriteBody([]byte(body))
+	}, http.ServerConfig{})
+	require.NoError(t, server.ServeTLS(listener, certs))
+	defer server.Close()
+	addr := listener.Addr().String()
+
+	// Test: Certificate is selected by SNI and TLS state is exposed on the request
+	cert, body := getOverTLS(t, addr, "a.example.com")
+	assert.Equal(t, []string{"a.example.com"}, cert.DNSNames)
+	assert.Equal(t, "a.example.com", body)
+
+	cert, body = getOverTLS(t, addr, "b.example.com")
+	assert.Equal(t, []string{"b.example.com"}, cert.DNSNames)
+	assert.Equal(t, "b.example.com", body)
+
+	// Test: Unknown server names fall back to the first certificate
+	cert, _ = getOverTLS(t, addr, "unknown.example.com")
+	assert.Equal(t, []string{"a.example.com"}, cert.DNSNames)
+
+	// Test: Changed certificate files are picked up on reload
+	certFile, keyFile = writeSelfSignedCert(t, dir, "b.example.com", 3)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+	require.NoError(t, certs.Reload())
+
+	cert, _ = getOverTLS(t, addr, "b.example.com")
+	assert.Equal(t, int64(3), cert.SerialNumber.Int64())
+}
+
+func TestClientCertificateAuth(t *testing.T) {
+	dir := t.TempDir()
+	certs := http.NewCertificateStore()
+	certFile, keyFile := writeSelfSignedCert(t, dir, "server.example.com", 1)
+	require.NoError(t, certs.AddFile(certFile, keyFile))
+
+	caCert, caKey := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(10),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	caFile, _ := writeTestCert(t, dir, "ca", caCert, caKey)
+	clientCAs, err := http.LoadCertPool(caFile)
+	require.NoError(t, err)
+
+	newClientCert := func(commonName string, serial int64) tls.Certificate {
+		cert, key := newTestCert(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: commonName},
+			DNSNames:     []string{commonName + ".internal"},
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, caCert, caKey)
+		return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := http.NewServer(http.RequireClientCert(http.AllowCommonNames("billing"))(func(w *http.ResponseWriter, req *http.Request) {
+		body := req.ClientCertificate().Subject.CommonName
+		w.WriteStatusLine(http.StatusOK)
+		w.WriteHeaders(http.GetDefaultResponseHeaders("text/plain", len(body)))
+		w.WriteBody([]byte(body))
+	}), http.ServerConfig{ClientCAs: clientCAs})
+	require.NoError(t, server.ServeTLS(listener, certs))
+	defer server.Close()
+
+	get := func(clientCerts ...tls.Certificate) (string, error) {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			ServerName:         "server.example.com",
+			InsecureSkipVerify: true,
+			Certificates:       clientCerts,
+		})
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+
+		if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: server.example.com\r\nConnection: close\r\n\r\n")); err != nil {
+			return "", err
+		}
+		statusLine, err := bufio.NewReader(conn).ReadString('\n')
+		return statusLine, err
+	}
+
+	// Test: Allowed client certificate
+	statusLine, err := get(newClientCert("billing", 11))
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 200 OK\r\n", statusLine)
+
+	// Test: Verified client certificate rejected by the route
+	statusLine, err = get(newClientCert("reporting", 12))
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 403 Forbidden\r\n", statusLine)
+
+	// Test: Missing client certificate fails the handshake
+	_, err = get()
+	assert.Error(t, err)
+
+	// Test: Matching on subject alternative names
+	allow := http.AllowDNSNames("reporting.internal")
+	cert, err := x509.ParseCertificate(newClientCert("reporting", 13).Certificate[0])
+	require.NoError(t, err)
+	assert.True(t, allow(cert))
+	assert.False(t, http.AllowCommonNames("billing")(cert))
+}
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type URL struct {
+	Scheme   string
+	Host     string
+	Path     string
+	RawPath  string
+	RawQuery string
+	Query    Values
+}
+
+type Values map[string][]string
+
+func (v Values) Get(key string) string {
+	if values := v[key]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (v Values) Has(key string) bool {
+	_, ok := v[key]
+	return ok
+}
+
+func (v Values) Add(key, value string) {
+	v[key] = append(v[key], value)
+}
+
+func parseRequestTarget(target string, form RequestTargetForm) (*URL, error) {
+	switch form {
+	case AsteriskForm:
+		return &URL{Path: "*", RawPath: "*", Query: Values{}}, nil
+	case AuthorityForm:
+		host, port, err := net.SplitHostPort(target)
+		if err != nil || host == "" || !isValidPort(port) {
+			return nil, fmt.Errorf("invalid authority: %s", target)
+		}
+		return &URL{Host: target, Query: Values{}}, nil
+	case AbsoluteForm:
+		return parseAbsoluteTarget(target)
+	default:
+		return parseOriginTarget(target)
+	}
+}
+
+func parseAbsoluteTarget(target string) (*URL, error) {
+	scheme, rest, _ := strings.Cut(target, "://")
+	if !isValidScheme(scheme) {
+		return nil, fmt.Errorf("invalid scheme: %s", scheme)
+	}
+
+	i := strings.IndexAny(rest, "/?")
+	if i == -1 {
+		i = len(rest)
+	}
+
+	host := rest[:i]
+	if host == "" || strings.ContainsAny(host, "@#") {
+		return nil, fmt.Errorf("invalid authority: %s", host)
+	}
+
+	if h, port, err := net.SplitHostPort(host); err == nil && (h == "" || !isValidPort(port)) {
+		return nil, fmt.Errorf("invalid authority: %s", host)
+	}
+
+	pathAndQuery := rest[i:]
+	if !strings.HasPrefix(pathAndQuery, "/") {
+		pathAndQuery = "/" + pathAndQuery
+	}
+
+	url, err := parseOriginTarget(pathAndQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Scheme = strings.ToLower(scheme)
+	url.Host = strings.ToLower(host)
+	return url, nil
+}
+
+func parseOriginTarget(target string) (*URL, error) {
+	rawPath, rawQuery, _ := strings.Cut(target, "?")
+
+	path, err := unescape(rawPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := ParseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return &URL{
+		Path:     removeDotSegments(path),
+		RawPath:  rawPath,
+		RawQuery: rawQuery,
+		Query:    query,
+	}, nil
+}
+
+func isValidScheme(scheme string) bool {
+	if scheme == "" || !unicode.IsLetter(rune(scheme[0])) {
+		return false
+	}
+
+	for _, c := range scheme {
+		if !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789+-.", c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isValidPort(port string) bool {
+	n, err := strconv.ParseUint(port, 10, 16)
+	return err == nil && n > 0
+}
+
+func ParseQuery(query string) (Values, error) {
+	values := Values{}
+
+	for pair := range strings.SplitSeq(query, "&") {
+		if pair == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := unescape(key, true)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err = unescape(value, true)
+		if err != nil {
+			return nil, err
+		}
+
+		values.Add(key, value)
+	}
+
+	return values, nil
+}
+
+func unescape(s string, plusIsSpace bool) (string, error) {
+	if !strings.ContainsAny(s, "%+") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return "", fmt.Errorf("invalid percent-encoding: %s", s)
+			}
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+		case s[i] == '+' && plusIsSpace:
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	decoded := b.String()
+	if strings.ContainsRune(decoded, 0) {
+		return "", errors.New("invalid percent-encoding: NUL byte")
+	}
+
+	return decoded, nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func removeDotSegments(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return path
+	}
+
+	segments := strings.Split(path[1:], "/")
+	output := make([]string, 0, len(segments))
+
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		switch segment {
+		case ".":
+			if last {
+				output = append(output, "")
+			}
+		case "..":
+			if len(output) > 0 {
+				output = output[:len(output)-1]
+			}
+			if last {
+				output = append(output, "")
+			}
+		default:
+			output = append(output, segment)
+		}
+	}
+
+	return "/" + strings.Join(output, "/")
+}
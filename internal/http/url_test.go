@@ -0,0 +1,106 @@
+package http_test
+
+import (
+	"testing"
+
+	"github.com/debobrad579/httpfromtcp/internal/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestURL(t *testing.T) {
+	// Test: Path and query are split
+	reader := &chunkReader{
+		data:            "GET /api?x=1&y=2 HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err := http.RequestFromReader(reader)
+	require.NoError(t, err)
+	require.NotNil(t, r.URL)
+	assert.Equal(t, "/api", r.URL.Path)
+	assert.Equal(t, "/api", r.URL.RawPath)
+	assert.Equal(t, "x=1&y=2", r.URL.RawQuery)
+	assert.Equal(t, "1", r.URL.Query.Get("x"))
+	assert.Equal(t, "2", r.URL.Query.Get("y"))
+	assert.Equal(t, "/api?x=1&y=2", r.RequestLine.RequestTarget)
+
+	// Test: Percent-decoded path keeps the raw form
+	reader = &chunkReader{
+		data:            "GET /my%20file.txt HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "/my file.txt", r.URL.Path)
+	assert.Equal(t, "/my%20file.txt", r.URL.RawPath)
+
+	// Test: Dot segments are removed from the path
+	reader = &chunkReader{
+		data:            "GET /static/../a/./b/ HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "/a/b/", r.URL.Path)
+
+	// Test: Dot segments cannot climb above the root
+	reader = &chunkReader{
+		data:            "GET /../../etc/passwd HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/passwd", r.URL.Path)
+
+	// Test: Multi-valued and encoded query parameters
+	reader = &chunkReader{
+		data:            "GET /search?tag=a&tag=b&q=hello+world%21&empty HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	r, err = http.RequestFromReader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, r.URL.Query["tag"])
+	assert.Equal(t, "hello world!", r.URL.Query.Get("q"))
+	assert.True(t, r.URL.Query.Has("empty"))
+	assert.False(t, r.URL.Query.Has("missing"))
+
+	// Test: Malformed percent-encoding in the path
+	reader = &chunkReader{
+		data:            "GET /bad%zzpath HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+
+	// Test: Truncated percent-encoding in the query
+	reader = &chunkReader{
+		data:            "GET /api?x=%4 HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+
+	// Test: Encoded NUL bytes are rejected
+	reader = &chunkReader{
+		data:            "GET /a%00b HTTP/1.1\r\nHost: localhost:42069\r\n\r\n",
+		numBytesPerRead: 3,
+	}
+	_, err = http.RequestFromReader(reader)
+	require.Error(t, err)
+}
+
+func TestParseQuery(t *testing.T) {
+	// Test: Standard query
+	values, err := http.ParseQuery("a=1&b=2&a=3")
+	require.NoError(t, err)
+	assert.Equal(t, http.Values{"a": {"1", "3"}, "b": {"2"}}, values)
+
+	// Test: Empty query
+	values, err = http.ParseQuery("")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	// Test: Invalid escape
+	_, err = http.ParseQuery("a=%G1")
+	require.Error(t, err)
+}